@@ -1,85 +1,534 @@
 package coroutinePool
 
 import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/jericho-yu/aid/array"
 )
 
 type (
-	// CoroutinePool 协程池
+	// CoroutinePool 协程池：工作协程从有界优先级队列中获取任务执行，工作协程数在最小、最大值之间按队列积压和空闲时间伸缩
 	CoroutinePool struct {
-		sw     sync.WaitGroup
-		size   uint // 批次最大执行数
-		funcs  *array.AnyArray[CoroutinePoolHandle]
-		wrongs *array.AnyArray[error]
-		lock   sync.RWMutex
+		lock        sync.RWMutex // 启动、关闭锁
+		closeLock   sync.Mutex   // 关闭、重新使用锁：等待工作协程退出时不持有lock
+		mu          sync.Mutex   // 队列、工作协程、统计锁
+		workers     sync.WaitGroup
+		minWorkers  uint          // 最小工作协程数
+		maxWorkers  uint          // 最大工作协程数
+		idleTimeout time.Duration // 工作协程空闲超时：超时后回收到最小工作协程数，为0时不回收
+		queueSize   uint          // 任务队列长度
+		running     bool
+		closed      bool
+		pending     chan struct{} // 待执行任务信号
+		slots       chan struct{} // 队列空位
+		tasks       taskHeap
+		seq         uint64
+		workerCount uint
+		busy        uint
+		counter     counter
+		parent      context.Context
+		ctx         context.Context
+		cancel      context.CancelCauseFunc
+		taskTimeout time.Duration
+		failFast    bool
+		firstOnce   sync.Once
+		firstErr    error
+		wrongs      *array.AnyArray[error]
+	}
+
+	// CoroutinePoolStats 协程池统计
+	CoroutinePoolStats struct {
+		Workers        uint          // 工作协程数
+		Running        uint          // 执行中的任务数
+		Queued         uint          // 队列中的任务数
+		Submitted      uint64        // 已提交任务数
+		Completed      uint64        // 已执行任务数：包括失败的任务
+		Failed         uint64        // 失败任务数
+		Skipped        uint64        // 因context取消未执行的任务数
+		AverageLatency time.Duration // 平均执行耗时
+		AverageWait    time.Duration // 平均排队耗时
+	}
+
+	// counter 统计计数
+	counter struct {
+		submitted, completed, failed, skipped uint64
+		latency, wait                         time.Duration
 	}
 
 	CoroutinePoolHandle func() error
+
+	// CoroutinePoolContextHandle 支持context的任务
+	CoroutinePoolContextHandle func(ctx context.Context) error
+
+	// task 队列中的任务
+	task struct {
+		ctx      context.Context
+		fn       CoroutinePoolContextHandle
+		onSkip   func(err error) // 任务因context取消未执行时的回调
+		priority int
+		seq      uint64
+		enqueued time.Time
+	}
+
+	// taskHeap 任务优先级队列：优先级高的先执行，相同优先级先进先出
+	taskHeap []*task
 )
 
 var (
 	CoroutinePoolApp CoroutinePool
 )
 
-// New 实例化：协程池
+// New 实例化：协程池；size为工作协程数，任务队列长度默认等于size
 func (*CoroutinePool) New(size uint) *CoroutinePool {
-	return &CoroutinePool{sw: sync.WaitGroup{}, size: size, funcs: array.Make[CoroutinePoolHandle](0), wrongs: array.Make[error](0)}
+	if size == 0 {
+		size = 1
+	}
+
+	return &CoroutinePool{minWorkers: size, maxWorkers: size, queueSize: size, parent: context.Background(), wrongs: array.Make[error](0)}
 }
 
-// Do 注册执行方法
-func (my *CoroutinePool) Do(fn CoroutinePoolHandle) {
-	my.funcs.Append(fn)
+// SetQueueSize 设置任务队列长度：最小为1；需在提交第一个任务前设置
+func (my *CoroutinePool) SetQueueSize(queueSize uint) *CoroutinePool {
 	my.lock.Lock()
 	defer my.lock.Unlock()
+
+	if !my.running {
+		my.queueSize = max(queueSize, 1)
+	}
+
+	return my
 }
 
-// Clean 清空
-func (my *CoroutinePool) Clean() *CoroutinePool {
+// SetScale 设置工作协程伸缩：队列积压时增加工作协程直到maxWorkers，空闲超过idleTimeout的工作协程回收到minWorkers；运行中可调整
+func (my *CoroutinePool) SetScale(minWorkers, maxWorkers uint, idleTimeout time.Duration) *CoroutinePool {
+	my.lock.RLock()
+	defer my.lock.RUnlock()
+
+	my.mu.Lock()
+	defer my.mu.Unlock()
+
+	my.maxWorkers = max(maxWorkers, 1)
+	my.minWorkers = min(minWorkers, my.maxWorkers)
+	my.idleTimeout = idleTimeout
+
+	if my.running && !my.closed {
+		for my.workerCount < my.minWorkers {
+			my.spawn()
+		}
+	}
+
+	return my
+}
+
+// SetContext 设置协程池context：context取消后，队列中未执行的任务不再执行，执行中任务的context同时取消；需在提交第一个任务前设置
+func (my *CoroutinePool) SetContext(ctx context.Context) *CoroutinePool {
 	my.lock.Lock()
 	defer my.lock.Unlock()
 
-	my.funcs = array.Make[CoroutinePoolHandle](0)
-	my.wrongs = array.Make[error](0)
+	if !my.running {
+		my.parent = ctx
+	}
 
 	return my
 }
 
-// Close 关闭并执行
-func (my *CoroutinePool) Close() []error {
+// SetTaskTimeout 设置单个任务超时：超时后取消任务的context，任务需自行响应context；需在提交第一个任务前设置
+func (my *CoroutinePool) SetTaskTimeout(timeout time.Duration) *CoroutinePool {
 	my.lock.Lock()
 	defer my.lock.Unlock()
 
-	if my.funcs.Len() == 0 {
-		return my.wrongs.ToSlice()
+	if !my.running {
+		my.taskTimeout = timeout
 	}
 
-	if my.size == 0 {
-		my.size = 1
+	return my
+}
+
+// SetFailFast 设置errgroup模式：第一个任务失败后取消协程池context，队列中未执行的任务不再执行；需在提交第一个任务前设置
+func (my *CoroutinePool) SetFailFast(failFast bool) *CoroutinePool {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	if !my.running {
+		my.failFast = failFast
 	}
 
-	chunk := my.funcs.Chunk(int(my.size))
-	my.sw.Add(len(chunk))
+	return my
+}
 
-	for _, funcs := range chunk {
-		go func() {
-			defer my.sw.Done()
-			for _, fn := range funcs {
-				go func() { my.wrongs.Append(fn()) }()
-			}
-		}()
+// Do 提交任务：队列已满时阻塞；协程池已关闭时错误记录到错误列表
+func (my *CoroutinePool) Do(fn CoroutinePoolHandle) {
+	if err := my.Submit(fn); err != nil {
+		my.wrongs.Append(err)
 	}
+}
 
-	my.sw.Wait()
+// DoContext 提交支持context的任务：队列已满时阻塞；提交失败时错误记录到错误列表
+func (my *CoroutinePool) DoContext(ctx context.Context, fn CoroutinePoolContextHandle) {
+	if err := my.SubmitContext(ctx, fn); err != nil {
+		my.wrongs.Append(err)
+	}
+}
 
-	return my.wrongs.ToSlice()
+// DoPriority 提交带优先级的任务：优先级高的先执行；队列已满时阻塞；提交失败时错误记录到错误列表
+func (my *CoroutinePool) DoPriority(priority int, fn CoroutinePoolHandle) {
+	if err := my.SubmitPriority(context.Background(), priority, func(context.Context) error { return fn() }); err != nil {
+		my.wrongs.Append(err)
+	}
 }
 
-// Wrongs 获取错误列表
-func (my *CoroutinePool) Wrongs() []error {
+// Submit 提交任务：队列已满时阻塞等待
+func (my *CoroutinePool) Submit(fn CoroutinePoolHandle) error {
+	return my.submit(&task{ctx: context.Background(), fn: func(context.Context) error { return fn() }}, true)
+}
+
+// SubmitContext 提交支持context的任务：队列已满时阻塞等待，ctx取消时返回ctx的错误；任务执行时的context同时受ctx和协程池context控制
+func (my *CoroutinePool) SubmitContext(ctx context.Context, fn CoroutinePoolContextHandle) error {
+	return my.submit(&task{ctx: ctx, fn: fn}, true)
+}
+
+// SubmitPriority 提交带优先级的支持context的任务：优先级高的先执行，相同优先级先进先出
+func (my *CoroutinePool) SubmitPriority(ctx context.Context, priority int, fn CoroutinePoolContextHandle) error {
+	return my.submit(&task{ctx: ctx, fn: fn, priority: priority}, true)
+}
+
+// TrySubmit 提交任务：队列已满时立即返回QueueFullErr
+func (my *CoroutinePool) TrySubmit(fn CoroutinePoolHandle) error {
+	return my.submit(&task{ctx: context.Background(), fn: func(context.Context) error { return fn() }}, false)
+}
+
+// submit 提交任务到队列
+func (my *CoroutinePool) submit(t *task, block bool) error {
 	my.lock.RLock()
+
+	if err := my.ensureStarted(); err != nil {
+		my.lock.RUnlock()
+		return err
+	}
+
+	if block {
+		// 等待队列空位时不持有读锁，避免与Close互相等待
+		slots := my.slots
+		my.lock.RUnlock()
+
+		select {
+		case slots <- struct{}{}:
+		case <-t.ctx.Done():
+			return t.ctx.Err()
+		}
+
+		my.lock.RLock()
+		if my.closed || !my.running || my.slots != slots {
+			my.lock.RUnlock()
+			return PoolClosedErr.New("")
+		}
+	} else {
+		select {
+		case my.slots <- struct{}{}:
+		default:
+			my.lock.RUnlock()
+			return QueueFullErr.New(fmt.Sprintf("队列长度：%d", cap(my.slots)))
+		}
+	}
 	defer my.lock.RUnlock()
 
+	my.mu.Lock()
+	my.seq++
+	t.seq, t.enqueued = my.seq, time.Now()
+	heap.Push(&my.tasks, t)
+	my.counter.submitted++
+	// 积压的任务多于空闲工作协程时扩容
+	if uint(my.tasks.Len()) > my.workerCount-my.busy && my.workerCount < my.maxWorkers {
+		my.spawn()
+	}
+	my.mu.Unlock()
+
+	my.pending <- struct{}{}
+
+	return nil
+}
+
+// ensureStarted 首次提交任务时启动工作协程（调用方需持有读锁）
+func (my *CoroutinePool) ensureStarted() error {
+	if my.closed {
+		return PoolClosedErr.New("")
+	}
+
+	if !my.running {
+		// 升级为写锁启动工作协程
+		my.lock.RUnlock()
+		my.lock.Lock()
+		if !my.running && !my.closed {
+			my.start()
+		}
+		my.lock.Unlock()
+		my.lock.RLock()
+
+		if my.closed || !my.running {
+			return PoolClosedErr.New("")
+		}
+	}
+
+	return nil
+}
+
+// start 启动工作协程（调用方需持有写锁）
+func (my *CoroutinePool) start() {
+	my.pending = make(chan struct{}, my.queueSize)
+	my.slots = make(chan struct{}, my.queueSize)
+	my.ctx, my.cancel = context.WithCancelCause(my.parent)
+	my.running = true
+
+	my.mu.Lock()
+	defer my.mu.Unlock()
+
+	for my.workerCount < my.minWorkers {
+		my.spawn()
+	}
+}
+
+// spawn 启动一个工作协程（调用方需持有mu）
+func (my *CoroutinePool) spawn() {
+	my.workerCount++
+	my.workers.Add(1)
+	go my.work(my.ctx, my.pending, my.slots)
+}
+
+// work 工作协程：执行队列中的任务直到队列关闭，空闲超时时回收
+func (my *CoroutinePool) work(ctx context.Context, pending, slots chan struct{}) {
+	defer my.workers.Done()
+
+	for {
+		my.mu.Lock()
+		idleTimeout := my.idleTimeout
+		my.mu.Unlock()
+
+		var (
+			idle  <-chan time.Time
+			timer *time.Timer
+		)
+		if idleTimeout > 0 {
+			timer = time.NewTimer(idleTimeout)
+			idle = timer.C
+		}
+
+		select {
+		case _, ok := <-pending:
+			if timer != nil {
+				timer.Stop()
+			}
+
+			my.mu.Lock()
+			if !ok {
+				my.workerCount--
+				my.mu.Unlock()
+				return
+			}
+			t := heap.Pop(&my.tasks).(*task)
+			my.busy++
+			my.mu.Unlock()
+
+			<-slots
+			my.execute(ctx, t)
+		case <-idle:
+			my.mu.Lock()
+			if my.workerCount > my.minWorkers && my.tasks.Len() == 0 {
+				my.workerCount--
+				my.mu.Unlock()
+				return
+			}
+			my.mu.Unlock()
+		}
+	}
+}
+
+// execute 执行任务并记录统计
+func (my *CoroutinePool) execute(ctx context.Context, t *task) {
+	start := time.Now()
+	skipped, err := my.run(ctx, t)
+	latency := time.Since(start)
+
+	my.mu.Lock()
+	my.busy--
+	if skipped {
+		my.counter.skipped++
+	} else {
+		my.counter.completed++
+		my.counter.latency += latency
+		my.counter.wait += start.Sub(t.enqueued)
+		if err != nil {
+			my.counter.failed++
+		}
+	}
+	my.mu.Unlock()
+
+	if err != nil {
+		my.wrongs.Append(err)
+	}
+}
+
+// run 执行任务：协程池或任务的context已取消时跳过任务，返回是否跳过
+func (my *CoroutinePool) run(ctx context.Context, t *task) (bool, error) {
+	if ctx.Err() != nil || t.ctx.Err() != nil {
+		err := context.Cause(t.ctx)
+		if ctx.Err() != nil {
+			err = context.Cause(ctx)
+		}
+		if t.onSkip != nil {
+			t.onSkip(err)
+		}
+
+		// errgroup模式下只记录第一个失败任务的错误
+		if my.failFast {
+			return true, nil
+		}
+		return true, err
+	}
+
+	taskCtx, cancel := context.WithCancelCause(t.ctx)
+	defer cancel(nil)
+	stop := context.AfterFunc(ctx, func() { cancel(context.Cause(ctx)) })
+	defer stop()
+
+	if my.taskTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		taskCtx, cancelTimeout = context.WithTimeout(taskCtx, my.taskTimeout)
+		defer cancelTimeout()
+	}
+
+	err := call(func() error { return t.fn(taskCtx) })
+	if err != nil && my.failFast {
+		my.firstOnce.Do(func() {
+			my.firstErr = err
+			my.cancel(err)
+		})
+	}
+
+	return false, err
+}
+
+// call 执行任务：任务panic时转换为TaskPanicErr
+func call(fn CoroutinePoolHandle) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = TaskPanicErr.New(fmt.Sprintf("%v", r))
+		}
+	}()
+
+	return fn()
+}
+
+// Clean 清空错误列表：已关闭的协程池可重新使用；关闭中时等待关闭完成
+func (my *CoroutinePool) Clean() *CoroutinePool {
+	my.closeLock.Lock()
+	defer my.closeLock.Unlock()
+
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.closed = false
+	my.wrongs.Clean()
+
+	if !my.running {
+		my.firstOnce = sync.Once{}
+		my.firstErr = nil
+	}
+
+	return my
+}
+
+// Close 关闭：不再接收新任务，等待队列中的任务全部执行完成后返回错误列表；可重复调用
+//
+// 等待期间执行中的任务提交新任务时返回PoolClosedErr
+func (my *CoroutinePool) Close() []error {
+	my.closeLock.Lock()
+	defer my.closeLock.Unlock()
+
+	my.lock.Lock()
+	my.closed = true
+	running := my.running
+	if running {
+		close(my.pending)
+	}
+	my.lock.Unlock()
+
+	if running {
+		my.workers.Wait()
+
+		my.lock.Lock()
+		my.cancel(PoolClosedErr.New(""))
+		my.running = false
+		my.lock.Unlock()
+	}
+
+	return my.wrongs.ToSlice()
+}
+
+// Wait 关闭并等待任务全部执行完成：errgroup模式返回第一个错误，否则返回errors.Join合并的所有错误
+func (my *CoroutinePool) Wait() error {
+	wrongs := my.Close()
+
+	if my.failFast {
+		return my.firstErr
+	}
+
+	return errors.Join(wrongs...)
+}
+
+// Err 获取errors.Join合并的所有错误
+func (my *CoroutinePool) Err() error { return errors.Join(my.Wrongs()...) }
+
+// Stats 获取统计
+func (my *CoroutinePool) Stats() *CoroutinePoolStats {
+	my.mu.Lock()
+	defer my.mu.Unlock()
+
+	stats := &CoroutinePoolStats{
+		Workers:   my.workerCount,
+		Running:   my.busy,
+		Queued:    uint(my.tasks.Len()),
+		Submitted: my.counter.submitted,
+		Completed: my.counter.completed,
+		Failed:    my.counter.failed,
+		Skipped:   my.counter.skipped,
+	}
+	if my.counter.completed > 0 {
+		stats.AverageLatency = my.counter.latency / time.Duration(my.counter.completed)
+		stats.AverageWait = my.counter.wait / time.Duration(my.counter.completed)
+	}
+
+	return stats
+}
+
+// Wrongs 获取错误列表：只包含失败任务的错误
+func (my *CoroutinePool) Wrongs() []error {
 	return my.wrongs.ToSlice()
 }
+
+func (my taskHeap) Len() int { return len(my) }
+
+func (my taskHeap) Less(i, j int) bool {
+	if my[i].priority != my[j].priority {
+		return my[i].priority > my[j].priority
+	}
+
+	return my[i].seq < my[j].seq
+}
+
+func (my taskHeap) Swap(i, j int) { my[i], my[j] = my[j], my[i] }
+
+func (my *taskHeap) Push(x any) { *my = append(*my, x.(*task)) }
+
+func (my *taskHeap) Pop() any {
+	old := *my
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	*my = old[:len(old)-1]
+
+	return t
+}
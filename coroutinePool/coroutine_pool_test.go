@@ -1,6 +1,13 @@
 package coroutinePool
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
 
 func Test1(t *testing.T) {
 	t.Run("test1", func(t *testing.T) {
@@ -17,3 +24,266 @@ func Test1(t *testing.T) {
 		}
 	})
 }
+
+// waitStats 等待统计满足条件
+func waitStats(t *testing.T, pool *CoroutinePool, ok func(stats *CoroutinePoolStats) bool) *CoroutinePoolStats {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		stats := pool.Stats()
+		if ok(stats) {
+			return stats
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("统计不满足条件：%+v", stats)
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestCoroutinePoolConcurrency(t *testing.T) {
+	var (
+		running, peak, done atomic.Int32
+		started             = make(chan struct{}, 10)
+		release             = make(chan struct{})
+	)
+
+	pool := CoroutinePoolApp.New(2).SetQueueSize(10)
+	for range 10 {
+		pool.Do(func() error {
+			current := running.Add(1)
+			for {
+				old := peak.Load()
+				if current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+			started <- struct{}{}
+			<-release
+			running.Add(-1)
+			done.Add(1)
+			return nil
+		})
+	}
+
+	<-started
+	<-started
+	close(release)
+	pool.Close()
+
+	if peak.Load() > 2 {
+		t.Fatalf("并发数超过限制：%d", peak.Load())
+	}
+	if done.Load() != 10 {
+		t.Fatalf("关闭前未执行完成：%d", done.Load())
+	}
+}
+
+func TestCoroutinePoolTrySubmit(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		block   = make(chan struct{})
+	)
+
+	pool := CoroutinePoolApp.New(1).SetQueueSize(1)
+	_ = pool.Submit(func() error { close(started); <-block; return nil })
+	<-started
+	_ = pool.Submit(func() error { return nil })
+
+	if err := pool.TrySubmit(func() error { return nil }); !errors.Is(err, &QueueFullErr) {
+		t.Fatalf("队列已满时应返回QueueFullErr：%v", err)
+	}
+
+	close(block)
+	pool.Close()
+
+	if err := pool.TrySubmit(func() error { return nil }); !errors.Is(err, &PoolClosedErr) {
+		t.Fatalf("关闭后应返回PoolClosedErr：%v", err)
+	}
+}
+
+func TestCoroutinePoolSubmitWhileClosing(t *testing.T) {
+	var (
+		started  = make(chan struct{})
+		gate     = make(chan struct{})
+		nested   = make(chan error, 1)
+		blocked  = make(chan error, 1)
+		closed   = make(chan struct{})
+		executed atomic.Int32
+	)
+
+	pool := CoroutinePoolApp.New(1).SetQueueSize(1)
+	// 执行中的任务在关闭期间提交新任务
+	_ = pool.Submit(func() error {
+		close(started)
+		<-gate
+		nested <- pool.Submit(func() error { return nil })
+		return nil
+	})
+	<-started
+	_ = pool.Submit(func() error { executed.Add(1); return nil })
+
+	// 队列已满时阻塞的提交
+	go func() { blocked <- pool.Submit(func() error { executed.Add(1); return nil }) }()
+
+	go func() {
+		pool.Close()
+		close(closed)
+	}()
+	for {
+		pool.lock.RLock()
+		closing := pool.closed
+		pool.lock.RUnlock()
+		if closing {
+			break
+		}
+		runtime.Gosched()
+	}
+	close(gate)
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("关闭期间提交任务不应死锁")
+	}
+
+	if err := <-nested; !errors.Is(err, &PoolClosedErr) {
+		t.Fatalf("关闭期间提交应返回PoolClosedErr：%v", err)
+	}
+	if err := <-blocked; err != nil && !errors.Is(err, &PoolClosedErr) {
+		t.Fatalf("阻塞的提交应成功或返回PoolClosedErr：%v", err)
+	} else if err == nil && executed.Load() != 2 {
+		t.Fatalf("提交成功的任务应执行：%d", executed.Load())
+	}
+}
+
+func TestCoroutinePoolGo(t *testing.T) {
+	pool := CoroutinePoolApp.New(2)
+	defer pool.Close()
+
+	sum := Go(pool, func() (int, error) { return 1 + 2, nil })
+	if val, err := sum.Wait(); err != nil || val != 3 {
+		t.Fatalf("结果错误：%d %v", val, err)
+	}
+
+	panicked := Go(pool, func() (string, error) { panic("崩溃") })
+	if _, err := panicked.Wait(); !errors.Is(err, &TaskPanicErr) {
+		t.Fatalf("panic应转换为TaskPanicErr：%v", err)
+	}
+}
+
+func TestCoroutinePoolWrongs(t *testing.T) {
+	failed := errors.New("失败")
+
+	pool := CoroutinePoolApp.New(2)
+	pool.Do(func() error { return nil })
+	pool.Do(func() error { return failed })
+	pool.Do(func() error { return nil })
+
+	if wrongs := pool.Close(); len(wrongs) != 1 {
+		t.Fatalf("错误数量错误：%v", wrongs)
+	}
+	if !errors.Is(pool.Err(), failed) {
+		t.Fatalf("合并错误错误：%v", pool.Err())
+	}
+}
+
+func TestCoroutinePoolTaskTimeout(t *testing.T) {
+	pool := CoroutinePoolApp.New(1).SetTaskTimeout(10 * time.Millisecond)
+	pool.DoContext(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if err := pool.Wait(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("应返回超时错误：%v", err)
+	}
+}
+
+func TestCoroutinePoolContext(t *testing.T) {
+	var executed atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pool := CoroutinePoolApp.New(1).SetQueueSize(10).SetContext(ctx)
+	pool.Do(func() error { cancel(); return nil })
+	for range 5 {
+		pool.Do(func() error { executed.Add(1); return nil })
+	}
+
+	if err := pool.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("应返回取消错误：%v", err)
+	}
+	if executed.Load() != 0 {
+		t.Fatalf("取消后仍执行了任务：%d", executed.Load())
+	}
+}
+
+func TestCoroutinePoolFailFast(t *testing.T) {
+	failed := errors.New("失败")
+
+	pool := CoroutinePoolApp.New(2).SetQueueSize(10).SetFailFast(true)
+	pool.DoContext(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	pool.Do(func() error { return failed })
+	skipped := GoContext(context.Background(), pool, func(ctx context.Context) (int, error) { return 1, nil })
+
+	if err := pool.Wait(); err != failed {
+		t.Fatalf("应返回第一个错误：%v", err)
+	}
+	if _, err := skipped.Wait(); err == nil {
+		t.Fatal("未执行的任务应返回错误")
+	}
+}
+
+func TestCoroutinePoolPriority(t *testing.T) {
+	var (
+		order   []int
+		started = make(chan struct{})
+		block   = make(chan struct{})
+	)
+
+	pool := CoroutinePoolApp.New(1).SetQueueSize(10)
+	pool.Do(func() error { close(started); <-block; return nil })
+	<-started
+	for _, priority := range []int{1, 3, 2, 3} {
+		pool.DoPriority(priority, func() error { order = append(order, priority); return nil })
+	}
+	close(block)
+	pool.Close()
+
+	if len(order) != 4 || order[0] != 3 || order[1] != 3 || order[2] != 2 || order[3] != 1 {
+		t.Fatalf("执行顺序错误：%v", order)
+	}
+}
+
+func TestCoroutinePoolScale(t *testing.T) {
+	var (
+		started = make(chan struct{}, 4)
+		block   = make(chan struct{})
+	)
+
+	pool := CoroutinePoolApp.New(1).SetQueueSize(10).SetScale(1, 4, 20*time.Millisecond)
+	defer pool.Close()
+
+	for range 4 {
+		pool.Do(func() error { started <- struct{}{}; <-block; return nil })
+	}
+	for range 4 {
+		<-started
+	}
+
+	if stats := pool.Stats(); stats.Workers != 4 || stats.Running != 4 {
+		t.Fatalf("未扩容：%+v", stats)
+	}
+
+	close(block)
+	pool.Do(func() error { return errors.New("失败") })
+
+	stats := waitStats(t, pool, func(stats *CoroutinePoolStats) bool { return stats.Workers == 1 && stats.Completed == 5 })
+	if stats.Submitted != 5 || stats.Failed != 1 || stats.Queued != 0 || stats.Running != 0 {
+		t.Fatalf("统计错误：%+v", stats)
+	}
+}
@@ -0,0 +1,70 @@
+package coroutinePool
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jericho-yu/aid/array"
+	"github.com/jericho-yu/aid/myError"
+	"github.com/jericho-yu/aid/operation"
+)
+
+type (
+	PoolClosedError struct{ myError.MyError }
+	QueueFullError  struct{ myError.MyError }
+	TaskPanicError  struct{ myError.MyError }
+)
+
+var (
+	PoolClosedErr PoolClosedError
+	QueueFullErr  QueueFullError
+	TaskPanicErr  TaskPanicError
+)
+
+func (*PoolClosedError) New(msg string) myError.IMyError {
+	return &PoolClosedError{myError.MyError{Msg: array.NewDestruction("协程池已关闭", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*PoolClosedError) Wrap(err error) myError.IMyError {
+	return &PoolClosedError{myError.MyError{Msg: fmt.Errorf("协程池已关闭"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*PoolClosedError) Panic() myError.IMyError {
+	return &PoolClosedError{myError.MyError{Msg: "协程池已关闭"}}
+}
+
+func (my *PoolClosedError) Error() string { return my.Msg }
+
+func (my *PoolClosedError) Is(target error) bool { return reflect.DeepEqual(target, &PoolClosedErr) }
+
+func (*QueueFullError) New(msg string) myError.IMyError {
+	return &QueueFullError{myError.MyError{Msg: array.NewDestruction("任务队列已满", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*QueueFullError) Wrap(err error) myError.IMyError {
+	return &QueueFullError{myError.MyError{Msg: fmt.Errorf("任务队列已满"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*QueueFullError) Panic() myError.IMyError {
+	return &QueueFullError{myError.MyError{Msg: "任务队列已满"}}
+}
+
+func (my *QueueFullError) Error() string { return my.Msg }
+
+func (my *QueueFullError) Is(target error) bool { return reflect.DeepEqual(target, &QueueFullErr) }
+
+func (*TaskPanicError) New(msg string) myError.IMyError {
+	return &TaskPanicError{myError.MyError{Msg: array.NewDestruction("任务异常", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*TaskPanicError) Wrap(err error) myError.IMyError {
+	return &TaskPanicError{myError.MyError{Msg: fmt.Errorf("任务异常"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*TaskPanicError) Panic() myError.IMyError {
+	return &TaskPanicError{myError.MyError{Msg: "任务异常"}}
+}
+
+func (my *TaskPanicError) Error() string { return my.Msg }
+
+func (my *TaskPanicError) Is(target error) bool { return reflect.DeepEqual(target, &TaskPanicErr) }
@@ -0,0 +1,84 @@
+package coroutinePool
+
+import "context"
+
+type (
+	// Future 异步任务结果
+	Future[T any] struct {
+		done chan struct{}
+		val  T
+		err  error
+	}
+)
+
+// Go 提交有返回值的任务：队列已满时阻塞等待；任务的错误同时记录到协程池错误列表
+func Go[T any](pool *CoroutinePool, fn func() (T, error)) *Future[T] {
+	return GoContext(context.Background(), pool, func(context.Context) (T, error) { return fn() })
+}
+
+// GoContext 提交支持context的有返回值的任务：队列已满时阻塞等待，ctx取消时Future返回ctx的错误
+func GoContext[T any](ctx context.Context, pool *CoroutinePool, fn func(ctx context.Context) (T, error)) *Future[T] {
+	future, t := newFuture(ctx, fn)
+	if err := pool.submit(t, true); err != nil {
+		future.resolve(future.val, err)
+	}
+
+	return future
+}
+
+// GoPriority 提交带优先级的支持context的有返回值的任务：优先级高的先执行
+func GoPriority[T any](ctx context.Context, pool *CoroutinePool, priority int, fn func(ctx context.Context) (T, error)) *Future[T] {
+	future, t := newFuture(ctx, fn)
+	t.priority = priority
+	if err := pool.submit(t, true); err != nil {
+		future.resolve(future.val, err)
+	}
+
+	return future
+}
+
+// TryGo 提交有返回值的任务：队列已满时Future立即返回QueueFullErr
+func TryGo[T any](pool *CoroutinePool, fn func() (T, error)) *Future[T] {
+	future, t := newFuture(context.Background(), func(context.Context) (T, error) { return fn() })
+	if err := pool.submit(t, false); err != nil {
+		future.resolve(future.val, err)
+	}
+
+	return future
+}
+
+// newFuture 创建Future和对应的任务
+func newFuture[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) (*Future[T], *task) {
+	future := &Future[T]{done: make(chan struct{})}
+
+	return future, &task{
+		ctx: ctx,
+		fn: func(ctx context.Context) error {
+			var val T
+			err := call(func() (err error) {
+				val, err = fn(ctx)
+				return err
+			})
+			future.resolve(val, err)
+
+			return err
+		},
+		onSkip: func(err error) { future.resolve(future.val, err) },
+	}
+}
+
+// resolve 设置结果
+func (my *Future[T]) resolve(val T, err error) {
+	my.val, my.err = val, err
+	close(my.done)
+}
+
+// Wait 等待任务完成并获取结果
+func (my *Future[T]) Wait() (T, error) {
+	<-my.done
+
+	return my.val, my.err
+}
+
+// Done 任务完成时关闭的通道
+func (my *Future[T]) Done() <-chan struct{} { return my.done }
@@ -0,0 +1,36 @@
+package digest
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// Md5File 计算文件md5摘要：流式读取，适用于大文件
+func Md5File(filename string) (string, error) { return fileDigest(filename, md5.New()) }
+
+// Sha256File 计算文件sha256摘要：流式读取，适用于大文件
+func Sha256File(filename string) (string, error) { return fileDigest(filename, sha256.New()) }
+
+// Sm3File 计算文件sm3摘要：流式读取，适用于大文件
+func Sm3File(filename string) (string, error) { return fileDigest(filename, sm3.New()) }
+
+// fileDigest 使用指定摘要算法计算文件摘要
+func fileDigest(filename string, h hash.Hash) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err = io.Copy(h, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
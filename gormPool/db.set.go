@@ -11,6 +11,7 @@ type (
 		Postgres  *PostgresSetting  `yaml:"postgres,omitempty"`
 		SqlServer *SqlServerSetting `yaml:"sqlServer,omitempty"`
 		CbitSql   *CbitSqlSetting   `yaml:"cbitSql,omitempty"`
+		Sqlite    *SqliteSetting    `yaml:"sqlite,omitempty"`
 	}
 
 	Common struct {
@@ -76,6 +77,12 @@ type (
 		Main *SqlServerConnection `yaml:"main"`
 	}
 
+	SqliteSetting struct {
+		Database string `yaml:"database"` // 数据库文件路径；内存模式下为数据库名称，同名共享数据库，为空时每次创建独立的数据库
+		Memory   bool   `yaml:"memory"`   // 内存模式
+		Params   string `yaml:"params"`   // 额外的链接参数，如：_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)
+	}
+
 	SqlServerConnection struct {
 		Username string `yaml:"username"`
 		Password string `yaml:"password"`
@@ -171,5 +178,9 @@ sqlServer:
     password: "Admin@1234"
     host: 127.0.0.1
     port: 9930
-    database: "tbl_test"`
+    database: "tbl_test"
+sqlite:
+  database: "tbl_test"
+  memory: true
+  params: "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"`
 }
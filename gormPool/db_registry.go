@@ -0,0 +1,323 @@
+package gormPool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jericho-yu/aid/honestMan"
+	"gorm.io/gorm"
+)
+
+type (
+	// DbRegistrySetting 多数据库配置：按名称配置多个数据库
+	DbRegistrySetting struct {
+		Databases map[string]*DbSetting `yaml:"databases"`
+	}
+
+	// DbRegistry 多数据库链接注册表：按名称管理多个链接池，同一个驱动可以同时链接多个数据库
+	DbRegistry struct {
+		lock  sync.RWMutex
+		pools map[string]GormPool
+	}
+)
+
+var (
+	DbRegistrySettingApp DbRegistrySetting
+	DbRegistryApp        DbRegistry
+)
+
+// New 初始化：多数据库配置
+func (*DbRegistrySetting) New(path string) *DbRegistrySetting {
+	var dbRegistrySetting *DbRegistrySetting = &DbRegistrySetting{}
+
+	if err := honestMan.HonestManApp.New(path).LoadYaml(dbRegistrySetting); err != nil {
+		return nil
+	}
+
+	return dbRegistrySetting
+}
+
+func (*DbRegistrySetting) ExampleYaml() string {
+	return `databases:
+  order:
+    common:
+      driver: "mysql"
+      maxOpenConns: 100
+      maxIdleConns: 20
+      maxLifetime: 100
+      maxIdleTime: 10
+    mysql:
+      database: "db_order"
+      charset: "utf8mb4"
+      collation: "utf8mb4_general_ci"
+      main:
+        username: "root"
+        password: "root"
+        host: 127.0.0.1
+        port: 3306
+  user:
+    common:
+      driver: "mysql"
+      maxOpenConns: 50
+      maxIdleConns: 10
+      maxLifetime: 100
+      maxIdleTime: 10
+    mysql:
+      database: "db_user"
+      charset: "utf8mb4"
+      collation: "utf8mb4_general_ci"
+      main:
+        username: "root"
+        password: "root"
+        host: 127.0.0.1
+        port: 3306
+  analytics:
+    common:
+      driver: "postgres"
+      maxOpenConns: 20
+      maxIdleConns: 5
+      maxLifetime: 100
+      maxIdleTime: 10
+    postgres:
+      main:
+        username: "postgres"
+        password: "postgres"
+        host: 127.0.0.1
+        port: 5432
+        database: "db_analytics"
+        sslmode: "disable"
+        timezone: "Asia/Shanghai"`
+}
+
+// New 实例化：多数据库链接注册表
+func (*DbRegistry) New() *DbRegistry { return &DbRegistry{pools: make(map[string]GormPool)} }
+
+// NewGormPool 按common.driver创建链接池：支持mysql、postgres、sqlServer、sqlite；cbitSql暂无链接池实现，返回DbSettingErr
+func NewGormPool(dbSetting *DbSetting) (GormPool, error) {
+	if dbSetting == nil || dbSetting.Common == nil {
+		return nil, DbSettingErr.New("缺少common配置")
+	}
+
+	switch strings.ToLower(dbSetting.Common.Driver) {
+	case "mysql":
+		return MySqlPoolApp.New(dbSetting)
+	case "postgres", "postgresql":
+		return PostgresPoolApp.New(dbSetting)
+	case "sqlserver":
+		return SqlServerPoolApp.New(dbSetting)
+	case "sqlite", "sqlite3":
+		return SqlitePoolApp.New(dbSetting)
+	case "cbitsql":
+		return nil, DbSettingErr.New("cbitSql驱动暂不支持创建链接池")
+	default:
+		return nil, DbSettingErr.New(fmt.Sprintf("不支持的驱动：%s", dbSetting.Common.Driver))
+	}
+}
+
+// Load 按配置创建并注册所有链接：任意一个失败时关闭本次创建的链接并返回错误，已注册的链接不受影响
+func (my *DbRegistry) Load(dbRegistrySetting *DbRegistrySetting) error {
+	if dbRegistrySetting == nil {
+		return DbSettingErr.New("缺少多数据库配置")
+	}
+
+	var (
+		pools = make(map[string]GormPool, len(dbRegistrySetting.Databases))
+		errs  []error
+	)
+
+	for name, dbSetting := range dbRegistrySetting.Databases {
+		if my.Has(name) {
+			errs = append(errs, DbExistsErr.New(name))
+			continue
+		}
+
+		pool, err := NewGormPool(dbSetting)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s：%w", name, err))
+			continue
+		}
+		pools[name] = pool
+	}
+
+	if len(errs) == 0 {
+		my.lock.Lock()
+		for name := range pools {
+			if _, exists := my.pools[name]; exists {
+				errs = append(errs, DbExistsErr.New(name))
+				break
+			}
+		}
+		if len(errs) == 0 {
+			for name, pool := range pools {
+				my.pools[name] = pool
+			}
+		}
+		my.lock.Unlock()
+	}
+
+	if len(errs) > 0 {
+		for _, pool := range pools {
+			_ = pool.Close()
+		}
+
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// Add 按配置创建并注册链接：用于运行时新增数据库，名称已存在时返回DbExistsErr
+func (my *DbRegistry) Add(name string, dbSetting *DbSetting) error {
+	if my.Has(name) {
+		return DbExistsErr.New(name)
+	}
+
+	pool, err := NewGormPool(dbSetting)
+	if err != nil {
+		return err
+	}
+
+	if err = my.AddPool(name, pool); err != nil {
+		_ = pool.Close()
+		return err
+	}
+
+	return nil
+}
+
+// AddPool 注册已创建的链接池：名称已存在时返回DbExistsErr
+func (my *DbRegistry) AddPool(name string, pool GormPool) error {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	if _, exists := my.pools[name]; exists {
+		return DbExistsErr.New(name)
+	}
+
+	my.pools[name] = pool
+
+	return nil
+}
+
+// Has 检查链接是否存在
+func (my *DbRegistry) Has(name string) bool {
+	my.lock.RLock()
+	defer my.lock.RUnlock()
+
+	_, exists := my.pools[name]
+
+	return exists
+}
+
+// Get 获取链接池：不存在时返回DbNotFoundErr
+func (my *DbRegistry) Get(name string) (GormPool, error) {
+	my.lock.RLock()
+	defer my.lock.RUnlock()
+
+	if pool, exists := my.pools[name]; exists {
+		return pool, nil
+	}
+
+	return nil, DbNotFoundErr.New(name)
+}
+
+// GetConn 获取数据库链接：不存在时返回DbNotFoundErr
+func (my *DbRegistry) GetConn(name string) (*gorm.DB, error) {
+	pool, err := my.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return pool.GetConn(), nil
+}
+
+// Names 获取所有链接名称（已排序）
+func (my *DbRegistry) Names() []string {
+	my.lock.RLock()
+	defer my.lock.RUnlock()
+
+	names := make([]string, 0, len(my.pools))
+	for name := range my.pools {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Ping 检查单个链接：不存在时返回DbNotFoundErr
+func (my *DbRegistry) Ping(ctx context.Context, name string) error {
+	pool, err := my.Get(name)
+	if err != nil {
+		return err
+	}
+
+	return pool.Ping(ctx)
+}
+
+// Health 并发检查所有链接：返回每个链接的检查结果，正常为nil
+func (my *DbRegistry) Health(ctx context.Context) map[string]error {
+	my.lock.RLock()
+	pools := make(map[string]GormPool, len(my.pools))
+	for name, pool := range my.pools {
+		pools[name] = pool
+	}
+	my.lock.RUnlock()
+
+	var (
+		lock   sync.Mutex
+		wg     sync.WaitGroup
+		result = make(map[string]error, len(pools))
+	)
+
+	for name, pool := range pools {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := pool.Ping(ctx)
+
+			lock.Lock()
+			result[name] = err
+			lock.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return result
+}
+
+// Remove 关闭并移除链接：不存在时返回DbNotFoundErr
+func (my *DbRegistry) Remove(name string) error {
+	my.lock.Lock()
+	pool, exists := my.pools[name]
+	delete(my.pools, name)
+	my.lock.Unlock()
+
+	if !exists {
+		return DbNotFoundErr.New(name)
+	}
+
+	return pool.Close()
+}
+
+// Close 关闭并移除所有链接：返回所有关闭失败的错误
+func (my *DbRegistry) Close() error {
+	my.lock.Lock()
+	pools := my.pools
+	my.pools = make(map[string]GormPool)
+	my.lock.Unlock()
+
+	var errs []error
+	for name, pool := range pools {
+		if err := pool.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s：%w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
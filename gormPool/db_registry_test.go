@@ -0,0 +1,145 @@
+package gormPool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// newTestSqliteSetting 内存模式的sqlite配置
+func newTestSqliteSetting() *DbSetting {
+	return &DbSetting{Common: &Common{Driver: "sqlite"}, Sqlite: &SqliteSetting{Memory: true}}
+}
+
+func TestNewGormPoolDriver(t *testing.T) {
+	tests := []struct {
+		name      string
+		dbSetting *DbSetting
+	}{
+		{"缺少common配置", &DbSetting{Sqlite: &SqliteSetting{Memory: true}}},
+		{"不支持的驱动", &DbSetting{Common: &Common{Driver: "oracle"}}},
+		{"cbitSql", &DbSetting{Common: &Common{Driver: "cbitSql"}, CbitSql: &CbitSqlSetting{Database: "db"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewGormPool(test.dbSetting); !errors.Is(err, &DbSettingErr) {
+				t.Fatalf("期望DbSettingErr，实际：%v", err)
+			}
+		})
+	}
+
+	pool, err := NewGormPool(newTestSqliteSetting())
+	if err != nil {
+		t.Fatalf("创建sqlite链接池失败：%v", err)
+	}
+	_ = pool.Close()
+}
+
+func TestDbRegistry(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "db.yaml")
+	content := "databases:\n  order:\n    common:\n      driver: sqlite\n    sqlite:\n      memory: true\n  user:\n    common:\n      driver: sqlite3\n    sqlite:\n      memory: true\n"
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("写入配置失败：%v", err)
+	}
+
+	registry := DbRegistryApp.New()
+	defer func() { _ = registry.Close() }()
+
+	if err := registry.Load(DbRegistrySettingApp.New(filename)); err != nil {
+		t.Fatalf("加载配置失败：%v", err)
+	}
+	if names := registry.Names(); !slices.Equal(names, []string{"order", "user"}) {
+		t.Fatalf("链接名称错误：%v", names)
+	}
+
+	t.Run("Load失败时不注册任何链接", func(t *testing.T) {
+		err := registry.Load(&DbRegistrySetting{Databases: map[string]*DbSetting{
+			"log":   newTestSqliteSetting(),
+			"order": newTestSqliteSetting(),
+			"bad":   {Common: &Common{Driver: "oracle"}},
+		}})
+		if !errors.Is(err, &DbExistsErr) || !errors.Is(err, &DbSettingErr) {
+			t.Fatalf("应返回所有错误：%v", err)
+		}
+		if registry.Has("log") {
+			t.Fatal("加载失败时不应注册新链接")
+		}
+	})
+
+	t.Run("Add与Get", func(t *testing.T) {
+		if err := registry.Add("log", newTestSqliteSetting()); err != nil {
+			t.Fatalf("添加链接失败：%v", err)
+		}
+		if err := registry.Add("log", newTestSqliteSetting()); !errors.Is(err, &DbExistsErr) {
+			t.Fatalf("期望DbExistsErr，实际：%v", err)
+		}
+		if err := registry.Add("bad", &DbSetting{Common: &Common{Driver: "oracle"}}); !errors.Is(err, &DbSettingErr) || registry.Has("bad") {
+			t.Fatalf("期望DbSettingErr，实际：%v", err)
+		}
+
+		// 不同名称的链接相互独立
+		order, err := registry.GetConn("order")
+		if err != nil {
+			t.Fatalf("获取链接失败：%v", err)
+		}
+		if err = order.AutoMigrate(&testUser{}); err != nil {
+			t.Fatalf("创建表失败：%v", err)
+		}
+		user, err := registry.GetConn("user")
+		if err != nil {
+			t.Fatalf("获取链接失败：%v", err)
+		}
+		if user.Migrator().HasTable(&testUser{}) {
+			t.Fatal("不同名称的链接不应共享数据库")
+		}
+
+		if _, err = registry.Get("not-exists"); !errors.Is(err, &DbNotFoundErr) {
+			t.Fatalf("期望DbNotFoundErr，实际：%v", err)
+		}
+		if _, err = registry.GetConn("not-exists"); !errors.Is(err, &DbNotFoundErr) {
+			t.Fatalf("期望DbNotFoundErr，实际：%v", err)
+		}
+	})
+
+	t.Run("Health", func(t *testing.T) {
+		if err := registry.Ping(context.Background(), "order"); err != nil {
+			t.Fatalf("检查链接失败：%v", err)
+		}
+		if err := registry.Ping(context.Background(), "not-exists"); !errors.Is(err, &DbNotFoundErr) {
+			t.Fatalf("期望DbNotFoundErr，实际：%v", err)
+		}
+
+		// 关闭的链接检查失败
+		pool, _ := registry.Get("log")
+		_ = pool.Close()
+
+		health := registry.Health(context.Background())
+		if len(health) != 3 || health["order"] != nil || health["user"] != nil || health["log"] == nil {
+			t.Fatalf("检查结果错误：%v", health)
+		}
+	})
+
+	t.Run("Remove与Close", func(t *testing.T) {
+		if err := registry.Remove("log"); err != nil || registry.Has("log") {
+			t.Fatalf("移除链接失败：%v", err)
+		}
+		if err := registry.Remove("log"); !errors.Is(err, &DbNotFoundErr) {
+			t.Fatalf("期望DbNotFoundErr，实际：%v", err)
+		}
+
+		pool, _ := registry.Get("order")
+		if err := registry.Close(); err != nil {
+			t.Fatalf("关闭链接失败：%v", err)
+		}
+		if len(registry.Names()) != 0 {
+			t.Fatalf("关闭后应移除所有链接：%v", registry.Names())
+		}
+		if err := pool.Ping(context.Background()); err == nil {
+			t.Fatal("关闭后链接应不可用")
+		}
+	})
+}
@@ -3,6 +3,7 @@ package gormPool
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 
 	"gorm.io/gorm"
 )
@@ -28,12 +29,17 @@ type (
 	// Condition 查询
 	Condition struct {
 		Key      string `json:"key"`      // SQL字段名称，如果有别名则需要带有别名
-		Operator string `json:"operator"` // 操作符：=、>、<、!=、<=、>=、<>、in、not in、between、not between、like、like%、%like、raw、join
+		Operator string `json:"operator"` // 操作符：=、>、<、!=、<=、>=、<>、in、not in、between、not between、like、like%、%like
 		Values   []any  `json:"values"`   // 查询条件值
 	}
 )
 
-var FinderApp Finder
+var (
+	FinderApp Finder
+
+	// identifierPattern 普通标识符：字母、数字、下划线，可带一级前缀，如：name、u.name
+	identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+)
 
 // New 实例化：查询帮助器
 func (*Finder) New(db *gorm.DB) *Finder { return &Finder{DB: db, Total: -1} }
@@ -241,6 +247,10 @@ func (my *Finder) Transaction(functions ...func(db *gorm.DB)) error {
 }
 
 // TryQueryFromFinderCondition 从请求体中获取查询条件
+//
+// Table、Key只能是普通标识符，否则记录FinderFieldErr；不再支持raw、join操作符，使用时记录FinderOperatorErr；错误通过DB.Error获取，之后不会查询
+//
+//go:fix 推荐使用：TryQueryFromFinderQuery
 func (my *Finder) TryQueryFromFinderCondition(finderCondition *FinderCondition) *Finder {
 	if finderCondition == nil {
 		return my
@@ -248,39 +258,49 @@ func (my *Finder) TryQueryFromFinderCondition(finderCondition *FinderCondition)
 
 	// 设置表名
 	if finderCondition.Table != nil && *finderCondition.Table != "" {
+		if !my.checkIdentifier(*finderCondition.Table) {
+			return my
+		}
 		my.DB.Table(*finderCondition.Table)
 	}
 
 	// 设置查询条件
 	if len(finderCondition.Queries) > 0 {
 		for _, query := range finderCondition.Queries {
+			option := "and"
+			if query.Option != nil {
+				option = *query.Option
+			}
+
 			for _, condition := range query.Conditions {
-				if condition.Key != "" {
+				if condition.Key != "" && len(condition.Values) > 0 {
+					if !my.checkIdentifier(condition.Key) {
+						return my
+					}
+
 					switch condition.Operator {
 					case "=", ">", "<", "!=", "<=", ">=", "<>":
 						// {key:"fieldName", operator:"=", values:["value"]}
-						my.TryQuery(*query.Option, fmt.Sprintf("%s %s ?", condition.Key, condition.Operator), condition.Values[0])
+						my.TryQuery(option, fmt.Sprintf("%s %s ?", condition.Key, condition.Operator), condition.Values[0])
 					case "in", "not in":
 						// {key:"fieldName", operator:"in", values:["value1", "value2"]}
-						my.TryQuery(*query.Option, fmt.Sprintf("%s %s (?)", condition.Key, condition.Operator), condition.Values[0])
+						my.TryQuery(option, fmt.Sprintf("%s %s (?)", condition.Key, condition.Operator), condition.Values[0])
 					case "between", "not between":
 						// {key:"fieldName", operator:"between", values:["value1", "value2"]}
-						my.TryQuery(*query.Option, fmt.Sprintf("%s %s ? and ?", condition.Key, condition.Operator), condition.Values...)
+						my.TryQuery(option, fmt.Sprintf("%s %s ? and ?", condition.Key, condition.Operator), condition.Values...)
 					case "like":
 						// {key:"fieldName", operator:"like", values:["value"]}
-						my.TryQuery(*query.Option, fmt.Sprintf("%s like ?", condition.Key), fmt.Sprintf("%%%s%%", condition.Values[0]))
+						my.TryQuery(option, fmt.Sprintf("%s like ?", condition.Key), fmt.Sprintf("%%%s%%", condition.Values[0]))
 					case "like%":
 						// {key:"fieldName", operator:"like%", values:["value"]}
-						my.TryQuery(*query.Option, fmt.Sprintf("%s like ?", condition.Key), fmt.Sprintf("%s%%", condition.Values[0]))
+						my.TryQuery(option, fmt.Sprintf("%s like ?", condition.Key), fmt.Sprintf("%s%%", condition.Values[0]))
 					case "%like":
 						// {key:"fieldName", operator:"%like", values:["value"]}
-						my.TryQuery(*query.Option, fmt.Sprintf("%s like ?", condition.Key), fmt.Sprintf("%%%s", condition.Values[0]))
-					case "join", "left join", "right join", "inner join", "outer join":
-						// {key:"otherName o", operator:"join", values["o.xxx = tableName.xxx where xxx = ? and yyy = ?","xxx-value","yyy-value"]}
-						my.TryQuery(*query.Option, fmt.Sprintf("%s %s", condition.Operator, condition.Key), condition.Values...)
-					case "raw":
-						// {key:"fieldName", operator:"raw", values:["> ?", 100]}
-						my.TryQuery(*query.Option, condition.Key, condition.Values...)
+						my.TryQuery(option, fmt.Sprintf("%s like ?", condition.Key), fmt.Sprintf("%%%s", condition.Values[0]))
+					case "join", "left join", "right join", "inner join", "outer join", "raw":
+						// 直接拼接SQL：请使用Ex或WhenFunc
+						_ = my.DB.AddError(FinderOperatorErr.New(fmt.Sprintf("%s：不再支持%s，请使用Ex或WhenFunc", condition.Key, condition.Operator)))
+						return my
 					}
 				}
 			}
@@ -301,6 +321,10 @@ func (my *Finder) TryQueryFromFinderCondition(finderCondition *FinderCondition)
 }
 
 // TryQueryFromMap 从map中解析参数并查询
+//
+// key与表别名只能是普通标识符，否则记录FinderFieldErr；不再支持raw、join操作符，使用时记录FinderOperatorErr；错误通过DB.Error获取，之后不会查询
+//
+//go:fix 推荐使用：TryQueryFromFinderQuery
 func (my *Finder) TryQueryFromMap(queries map[string][]any) *Finder {
 	for key, value := range queries {
 		var (
@@ -308,13 +332,24 @@ func (my *Finder) TryQueryFromMap(queries map[string][]any) *Finder {
 			operator string
 		)
 
+		if len(value) < 2 {
+			continue
+		}
+
 		if operator, ok = value[0].(string); !ok {
 			continue
 		}
 
+		if !my.checkIdentifier(key) {
+			return my
+		}
+
 		switch operator {
 		case "alias":
 			// 表别名：{"tableName": ["alias", "aliasName"]}
+			if !my.checkIdentifier(fmt.Sprintf("%v", value[1])) {
+				return my
+			}
 			tableAlias := fmt.Sprintf("%s as %s", key, value[1])
 			my.DB.Table(tableAlias)
 		case "=", ">", "<", "!=", "<=", ">=", "<>":
@@ -324,7 +359,10 @@ func (my *Finder) TryQueryFromMap(queries map[string][]any) *Finder {
 			// 包含或不包含操作：{"fieldName": ["in", ["value1", "value2"]]}
 			my.DB.Where(fmt.Sprintf("%s %s (?)", key, operator), value[1])
 		case "between", "not between":
-			// 范围查询：{"fieldName": ["between", ["value1", "value2"]]}
+			// 范围查询：{"fieldName": ["between", "value1", "value2"]}
+			if len(value) < 3 {
+				continue
+			}
 			my.DB.Where(fmt.Sprintf("%s %s ? and ?", key, operator), value[1], value[2])
 		case "like":
 			// 模糊查询：{"fieldName": ["like", "value"]}
@@ -335,19 +373,30 @@ func (my *Finder) TryQueryFromMap(queries map[string][]any) *Finder {
 		case "%like":
 			// 模糊查询：{"fieldName": ["%like", "value"]}
 			my.DB.Where(fmt.Sprintf("%s like ?", key), fmt.Sprintf("%%%s", value[1]))
-		case "join":
-			// 连接查询：{"otherTableName": ["join", "joinSql", "onCondition"]}
-			my.DB.Joins(key, value[1:]...)
-		case "raw":
-			// 原生查询：{"fieldName": ["raw", "> ?", 100]}
-			my.DB.Where(key, value[1:]...)
+		case "join", "raw":
+			// 直接拼接SQL：请使用Ex或WhenFunc
+			_ = my.DB.AddError(FinderOperatorErr.New(fmt.Sprintf("%s：不再支持%s，请使用Ex或WhenFunc", key, operator)))
+			return my
 		}
 	}
 
 	return my
 }
 
+// checkIdentifier 检查字段名、表名是否为普通标识符：不是时记录FinderFieldErr
+func (my *Finder) checkIdentifier(name string) bool {
+	if identifierPattern.MatchString(name) {
+		return true
+	}
+
+	_ = my.DB.AddError(FinderFieldErr.New(fmt.Sprintf("不是普通标识符：%s", name)))
+
+	return false
+}
+
 // TryAutoFindFromMap 自动填充查询条件并查询：使用map[string][]any
+//
+//go:fix 推荐使用：TryAutoFindFromFinderQuery
 func (my *Finder) TryAutoFindFromMap(queries map[string][]any, preloads []string, orders []string, page, size int, ret any) *Finder {
 	my.TryQueryFromMap(queries).TryPagination(page, size).TryOrder(orders...).Find(ret, preloads...)
 	if my.Total == -1 {
@@ -357,6 +406,8 @@ func (my *Finder) TryAutoFindFromMap(queries map[string][]any, preloads []string
 }
 
 // TryAutoFindFromFinderCondition 自动填充查询条件并查询：使用FinderCondition
+//
+//go:fix 推荐使用：TryAutoFindFromFinderQuery
 func (my *Finder) TryAutoFindFromFinderCondition(finderCondition *FinderCondition, page, size int, ret any) *Finder {
 	my.TryQueryFromFinderCondition(finderCondition).TryPagination(page, size).Find(ret)
 	if my.Total == -1 {
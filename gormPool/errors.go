@@ -0,0 +1,162 @@
+package gormPool
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jericho-yu/aid/array"
+	"github.com/jericho-yu/aid/myError"
+	"github.com/jericho-yu/aid/operation"
+)
+
+type (
+	DbSettingError      struct{ myError.MyError }
+	DbPingError         struct{ myError.MyError }
+	DbNotFoundError     struct{ myError.MyError }
+	DbExistsError       struct{ myError.MyError }
+	FinderFieldError    struct{ myError.MyError }
+	FinderOperatorError struct{ myError.MyError }
+	FinderValueError    struct{ myError.MyError }
+	FinderGroupError    struct{ myError.MyError }
+)
+
+var (
+	DbSettingErr      DbSettingError
+	DbPingErr         DbPingError
+	DbNotFoundErr     DbNotFoundError
+	DbExistsErr       DbExistsError
+	FinderFieldErr    FinderFieldError
+	FinderOperatorErr FinderOperatorError
+	FinderValueErr    FinderValueError
+	FinderGroupErr    FinderGroupError
+)
+
+func (*DbSettingError) New(msg string) myError.IMyError {
+	return &DbSettingError{myError.MyError{Msg: array.NewDestruction("数据库配置错误", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*DbSettingError) Wrap(err error) myError.IMyError {
+	return &DbSettingError{myError.MyError{Msg: fmt.Errorf("数据库配置错误"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*DbSettingError) Panic() myError.IMyError {
+	return &DbSettingError{myError.MyError{Msg: "数据库配置错误"}}
+}
+
+func (my *DbSettingError) Error() string { return my.Msg }
+
+func (my *DbSettingError) Is(target error) bool { return reflect.DeepEqual(target, &DbSettingErr) }
+
+func (*DbPingError) New(msg string) myError.IMyError {
+	return &DbPingError{myError.MyError{Msg: array.NewDestruction("数据库链接检查失败", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*DbPingError) Wrap(err error) myError.IMyError {
+	return &DbPingError{myError.MyError{Msg: fmt.Errorf("数据库链接检查失败"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*DbPingError) Panic() myError.IMyError {
+	return &DbPingError{myError.MyError{Msg: "数据库链接检查失败"}}
+}
+
+func (my *DbPingError) Error() string { return my.Msg }
+
+func (my *DbPingError) Is(target error) bool { return reflect.DeepEqual(target, &DbPingErr) }
+
+func (*DbNotFoundError) New(msg string) myError.IMyError {
+	return &DbNotFoundError{myError.MyError{Msg: array.NewDestruction("数据库链接不存在", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*DbNotFoundError) Wrap(err error) myError.IMyError {
+	return &DbNotFoundError{myError.MyError{Msg: fmt.Errorf("数据库链接不存在"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*DbNotFoundError) Panic() myError.IMyError {
+	return &DbNotFoundError{myError.MyError{Msg: "数据库链接不存在"}}
+}
+
+func (my *DbNotFoundError) Error() string { return my.Msg }
+
+func (my *DbNotFoundError) Is(target error) bool { return reflect.DeepEqual(target, &DbNotFoundErr) }
+
+func (*DbExistsError) New(msg string) myError.IMyError {
+	return &DbExistsError{myError.MyError{Msg: array.NewDestruction("数据库链接已存在", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*DbExistsError) Wrap(err error) myError.IMyError {
+	return &DbExistsError{myError.MyError{Msg: fmt.Errorf("数据库链接已存在"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*DbExistsError) Panic() myError.IMyError {
+	return &DbExistsError{myError.MyError{Msg: "数据库链接已存在"}}
+}
+
+func (my *DbExistsError) Error() string { return my.Msg }
+
+func (my *DbExistsError) Is(target error) bool { return reflect.DeepEqual(target, &DbExistsErr) }
+
+func (*FinderFieldError) New(msg string) myError.IMyError {
+	return &FinderFieldError{myError.MyError{Msg: array.NewDestruction("查询字段不允许", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*FinderFieldError) Wrap(err error) myError.IMyError {
+	return &FinderFieldError{myError.MyError{Msg: fmt.Errorf("查询字段不允许"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*FinderFieldError) Panic() myError.IMyError {
+	return &FinderFieldError{myError.MyError{Msg: "查询字段不允许"}}
+}
+
+func (my *FinderFieldError) Error() string { return my.Msg }
+
+func (my *FinderFieldError) Is(target error) bool { return reflect.DeepEqual(target, &FinderFieldErr) }
+
+func (*FinderOperatorError) New(msg string) myError.IMyError {
+	return &FinderOperatorError{myError.MyError{Msg: array.NewDestruction("查询操作符不允许", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*FinderOperatorError) Wrap(err error) myError.IMyError {
+	return &FinderOperatorError{myError.MyError{Msg: fmt.Errorf("查询操作符不允许"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*FinderOperatorError) Panic() myError.IMyError {
+	return &FinderOperatorError{myError.MyError{Msg: "查询操作符不允许"}}
+}
+
+func (my *FinderOperatorError) Error() string { return my.Msg }
+
+func (my *FinderOperatorError) Is(target error) bool {
+	return reflect.DeepEqual(target, &FinderOperatorErr)
+}
+
+func (*FinderValueError) New(msg string) myError.IMyError {
+	return &FinderValueError{myError.MyError{Msg: array.NewDestruction("查询条件值错误", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*FinderValueError) Wrap(err error) myError.IMyError {
+	return &FinderValueError{myError.MyError{Msg: fmt.Errorf("查询条件值错误"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*FinderValueError) Panic() myError.IMyError {
+	return &FinderValueError{myError.MyError{Msg: "查询条件值错误"}}
+}
+
+func (my *FinderValueError) Error() string { return my.Msg }
+
+func (my *FinderValueError) Is(target error) bool { return reflect.DeepEqual(target, &FinderValueErr) }
+
+func (*FinderGroupError) New(msg string) myError.IMyError {
+	return &FinderGroupError{myError.MyError{Msg: array.NewDestruction("查询条件分组错误", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*FinderGroupError) Wrap(err error) myError.IMyError {
+	return &FinderGroupError{myError.MyError{Msg: fmt.Errorf("查询条件分组错误"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*FinderGroupError) Panic() myError.IMyError {
+	return &FinderGroupError{myError.MyError{Msg: "查询条件分组错误"}}
+}
+
+func (my *FinderGroupError) Error() string { return my.Msg }
+
+func (my *FinderGroupError) Is(target error) bool { return reflect.DeepEqual(target, &FinderGroupErr) }
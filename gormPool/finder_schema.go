@@ -0,0 +1,468 @@
+package gormPool
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type (
+	// FieldType 查询字段类型：决定允许的操作符和值的校验方式
+	FieldType string
+
+	// FinderField 允许查询的字段
+	FinderField struct {
+		Column     string    // SQL字段名称，如果有别名则需要带有别名；只能由服务端定义
+		Type       FieldType // 字段类型
+		Filterable bool      // 允许作为查询条件
+		Sortable   bool      // 允许排序
+		Operators  []string  // 允许的操作符：为空时使用字段类型的默认操作符，不支持raw、join
+	}
+
+	// FinderSchema 查询白名单：客户端只能使用白名单中的字段名、操作符和预加载，字段名映射为服务端定义的SQL字段
+	FinderSchema struct {
+		fields        map[string]*FinderField
+		preloads      map[string]struct{}
+		maxDepth      int
+		maxConditions int
+	}
+
+	// FinderQuery 查询条件：可由客户端提交
+	FinderQuery struct {
+		Where    *FinderGroup `json:"where,omitempty"`    // 查询条件
+		Orders   []string     `json:"orders,omitempty"`   // 排序：字段名，可带asc、desc，如：["age desc", "name"]
+		Preloads []string     `json:"preloads,omitempty"` // 预加载
+	}
+
+	// FinderGroup 查询条件分组：可以嵌套
+	FinderGroup struct {
+		Option     string        `json:"option,omitempty"`     // 操作：and（默认）、or、not；not表示其中的条件全部满足时排除
+		Conditions []Condition   `json:"conditions,omitempty"` // 条件：Key为白名单中的字段名
+		Groups     []FinderGroup `json:"groups,omitempty"`     // 子分组
+	}
+
+	// finderBuilder 构建查询条件
+	finderBuilder struct {
+		schema     *FinderSchema
+		args       []any
+		conditions int
+	}
+)
+
+const (
+	FieldTypeString FieldType = "string"
+	FieldTypeNumber FieldType = "number"
+	FieldTypeBool   FieldType = "bool"
+	FieldTypeTime   FieldType = "time"
+)
+
+var (
+	FinderSchemaApp FinderSchema
+
+	// FieldTypeOperators 字段类型默认允许的操作符
+	FieldTypeOperators = map[FieldType][]string{
+		FieldTypeString: {"=", "!=", "<>", "in", "not in", "like", "like%", "%like", "is null", "is not null"},
+		FieldTypeNumber: {"=", "!=", "<>", ">", "<", ">=", "<=", "in", "not in", "between", "not between", "is null", "is not null"},
+		FieldTypeBool:   {"=", "!=", "<>", "is null", "is not null"},
+		FieldTypeTime:   {"=", "!=", "<>", ">", "<", ">=", "<=", "between", "not between", "is null", "is not null"},
+	}
+
+	// finderOperators 支持的全部操作符：自定义的字段操作符也只能从中选择
+	finderOperators = []string{"=", "!=", "<>", ">", "<", ">=", "<=", "in", "not in", "between", "not between", "like", "like%", "%like", "is null", "is not null"}
+
+	// FinderTimeLayouts 时间字段支持的字符串格式
+	FinderTimeLayouts = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+)
+
+// New 实例化：查询白名单；默认分组最多嵌套5层，最多50个条件
+func (*FinderSchema) New() *FinderSchema {
+	return &FinderSchema{
+		fields:        make(map[string]*FinderField),
+		preloads:      make(map[string]struct{}),
+		maxDepth:      5,
+		maxConditions: 50,
+	}
+}
+
+// Field 添加允许查询和排序的字段：name为客户端使用的字段名，column为SQL字段名称
+func (my *FinderSchema) Field(name, column string, fieldType FieldType) *FinderSchema {
+	return my.AddField(name, FinderField{Column: column, Type: fieldType, Filterable: true, Sortable: true})
+}
+
+// AddField 添加字段
+func (my *FinderSchema) AddField(name string, field FinderField) *FinderSchema {
+	my.fields[name] = &field
+
+	return my
+}
+
+// AllowPreloads 添加允许的预加载
+func (my *FinderSchema) AllowPreloads(preloads ...string) *FinderSchema {
+	for _, preload := range preloads {
+		my.preloads[preload] = struct{}{}
+	}
+
+	return my
+}
+
+// SetMaxDepth 设置分组最大嵌套层数
+func (my *FinderSchema) SetMaxDepth(maxDepth int) *FinderSchema {
+	my.maxDepth = max(maxDepth, 1)
+
+	return my
+}
+
+// SetMaxConditions 设置最大条件数量
+func (my *FinderSchema) SetMaxConditions(maxConditions int) *FinderSchema {
+	my.maxConditions = max(maxConditions, 1)
+
+	return my
+}
+
+// Scope 校验并构建查询：返回可用于gorm.Scopes的方法；任何不在白名单中的输入都返回错误，不会生成SQL
+func (my *FinderSchema) Scope(finderQuery *FinderQuery) (func(db *gorm.DB) *gorm.DB, error) {
+	if finderQuery == nil {
+		return func(db *gorm.DB) *gorm.DB { return db }, nil
+	}
+
+	var (
+		builder  = &finderBuilder{schema: my}
+		where    string
+		orders   []string
+		preloads []string
+		err      error
+	)
+
+	if finderQuery.Where != nil {
+		if where, err = builder.group("where", finderQuery.Where, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	for idx, order := range finderQuery.Orders {
+		column, direction, err := my.order(fmt.Sprintf("orders[%d]", idx), order)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, fmt.Sprintf("%s %s", column, direction))
+	}
+
+	for idx, preload := range finderQuery.Preloads {
+		if _, exists := my.preloads[preload]; !exists {
+			return nil, FinderFieldErr.New(fmt.Sprintf("preloads[%d]：不允许预加载：%s", idx, preload))
+		}
+		preloads = append(preloads, preload)
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if where != "" {
+			db = db.Where(where, builder.args...)
+		}
+		for _, order := range orders {
+			db = db.Order(order)
+		}
+		// 使用校验时的副本：之后修改finderQuery不影响查询
+		for _, preload := range preloads {
+			db = db.Preload(preload)
+		}
+
+		return db
+	}, nil
+}
+
+// FromFinderCondition 将FinderCondition转换为FinderQuery：Queries中的每一项转换为一个分组，分组之间为and；忽略Table
+//
+// not保持TryQueryFromFinderCondition的语义：每个条件单独取反（NOT a AND NOT b），而不是整组取反（NOT (a AND b)）；
+// or的语义有变化：原方法中每个or条件与之前的所有条件为or，转换后or分组内的条件之间为or，分组整体与其他分组为and
+func (*FinderSchema) FromFinderCondition(finderCondition *FinderCondition) *FinderQuery {
+	if finderCondition == nil {
+		return nil
+	}
+
+	where := &FinderGroup{Option: "and"}
+	for _, query := range finderCondition.Queries {
+		group := FinderGroup{Conditions: query.Conditions}
+		if query.Option != nil {
+			group.Option = *query.Option
+		}
+
+		// not：每个条件转换为单独的分组
+		if strings.ToLower(group.Option) == "not" {
+			for _, condition := range query.Conditions {
+				where.Groups = append(where.Groups, FinderGroup{Option: group.Option, Conditions: []Condition{condition}})
+			}
+			continue
+		}
+
+		where.Groups = append(where.Groups, group)
+	}
+
+	return &FinderQuery{Where: where, Orders: finderCondition.Orders, Preloads: finderCondition.Preloads}
+}
+
+// order 校验排序
+func (my *FinderSchema) order(path, order string) (string, string, error) {
+	parts := strings.Fields(order)
+	if len(parts) == 0 || len(parts) > 2 {
+		return "", "", FinderFieldErr.New(fmt.Sprintf("%s：排序格式错误：%s", path, order))
+	}
+
+	field, exists := my.fields[parts[0]]
+	if !exists || !field.Sortable {
+		return "", "", FinderFieldErr.New(fmt.Sprintf("%s：不允许排序：%s", path, parts[0]))
+	}
+
+	direction := "asc"
+	if len(parts) == 2 {
+		direction = strings.ToLower(parts[1])
+		if direction != "asc" && direction != "desc" {
+			return "", "", FinderFieldErr.New(fmt.Sprintf("%s：排序方向错误：%s", path, parts[1]))
+		}
+	}
+
+	return field.Column, direction, nil
+}
+
+// group 构建分组：空分组返回空字符串
+func (my *finderBuilder) group(path string, group *FinderGroup, depth int) (string, error) {
+	if depth > my.schema.maxDepth {
+		return "", FinderGroupErr.New(fmt.Sprintf("%s：超过最大嵌套层数%d", path, my.schema.maxDepth))
+	}
+
+	option := strings.ToLower(group.Option)
+	if option == "" {
+		option = "and"
+	}
+	if option != "and" && option != "or" && option != "not" {
+		return "", FinderGroupErr.New(fmt.Sprintf("%s：不支持的操作：%s", path, group.Option))
+	}
+
+	var items []string
+	for idx := range group.Conditions {
+		item, err := my.condition(fmt.Sprintf("%s.conditions[%d]", path, idx), &group.Conditions[idx])
+		if err != nil {
+			return "", err
+		}
+		items = append(items, item)
+	}
+	for idx := range group.Groups {
+		item, err := my.group(fmt.Sprintf("%s.groups[%d]", path, idx), &group.Groups[idx], depth+1)
+		if err != nil {
+			return "", err
+		}
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+
+	switch {
+	case len(items) == 0:
+		return "", nil
+	case option == "or":
+		return fmt.Sprintf("(%s)", strings.Join(items, " OR ")), nil
+	case option == "not":
+		return fmt.Sprintf("NOT (%s)", strings.Join(items, " AND ")), nil
+	default:
+		return fmt.Sprintf("(%s)", strings.Join(items, " AND ")), nil
+	}
+}
+
+// condition 构建条件：字段名映射为SQL字段，值全部作为参数传递
+func (my *finderBuilder) condition(path string, condition *Condition) (string, error) {
+	if my.conditions++; my.conditions > my.schema.maxConditions {
+		return "", FinderGroupErr.New(fmt.Sprintf("%s：超过最大条件数量%d", path, my.schema.maxConditions))
+	}
+
+	field, exists := my.schema.fields[condition.Key]
+	if !exists || !field.Filterable {
+		return "", FinderFieldErr.New(fmt.Sprintf("%s：不允许查询：%s", path, condition.Key))
+	}
+
+	operator := strings.ToLower(strings.Join(strings.Fields(condition.Operator), " "))
+	operators := field.Operators
+	if len(operators) == 0 {
+		operators = FieldTypeOperators[field.Type]
+	}
+	if !slices.Contains(operators, operator) || !slices.Contains(finderOperators, operator) {
+		return "", FinderOperatorErr.New(fmt.Sprintf("%s：字段%s不允许使用：%s", path, condition.Key, condition.Operator))
+	}
+
+	values := condition.Values
+	switch operator {
+	case "in", "not in":
+		values = flatten(values)
+		if len(values) == 0 {
+			return "", FinderValueErr.New(fmt.Sprintf("%s：%s至少需要1个值", path, operator))
+		}
+	case "between", "not between":
+		if len(values) != 2 {
+			return "", FinderValueErr.New(fmt.Sprintf("%s：%s需要2个值", path, operator))
+		}
+	case "is null", "is not null":
+		if len(values) != 0 {
+			return "", FinderValueErr.New(fmt.Sprintf("%s：%s不需要值", path, operator))
+		}
+		return fmt.Sprintf("%s %s", field.Column, strings.ToUpper(operator)), nil
+	default:
+		if len(values) != 1 {
+			return "", FinderValueErr.New(fmt.Sprintf("%s：%s需要1个值", path, operator))
+		}
+	}
+
+	args := make([]any, len(values))
+	for idx, value := range values {
+		arg, err := normalize(field.Type, value)
+		if err != nil {
+			return "", FinderValueErr.New(fmt.Sprintf("%s.values[%d]：%s", path, idx, err.Error()))
+		}
+		args[idx] = arg
+	}
+
+	switch operator {
+	case "in", "not in":
+		my.args = append(my.args, args)
+		return fmt.Sprintf("%s %s (?)", field.Column, strings.ToUpper(operator)), nil
+	case "between", "not between":
+		my.args = append(my.args, args...)
+		return fmt.Sprintf("%s %s ? AND ?", field.Column, strings.ToUpper(operator)), nil
+	case "like", "like%", "%like":
+		// 转义通配符：客户端的值只做字面匹配
+		val, ok := args[0].(string)
+		if !ok {
+			return "", FinderValueErr.New(fmt.Sprintf("%s.values[0]：%s只能用于字符串", path, operator))
+		}
+		pattern := escapeLike(val)
+		switch operator {
+		case "like":
+			pattern = "%" + pattern + "%"
+		case "like%":
+			pattern = pattern + "%"
+		case "%like":
+			pattern = "%" + pattern
+		}
+		my.args = append(my.args, pattern)
+		return fmt.Sprintf("%s LIKE ? ESCAPE '!'", field.Column), nil
+	default:
+		my.args = append(my.args, args[0])
+		return fmt.Sprintf("%s %s ?", field.Column, operator), nil
+	}
+}
+
+// flatten 展开in的值：支持["a", "b"]和[["a", "b"]]两种写法
+func flatten(values []any) []any {
+	if len(values) != 1 || values[0] == nil {
+		return values
+	}
+
+	ref := reflect.ValueOf(values[0])
+	if ref.Kind() != reflect.Slice && ref.Kind() != reflect.Array {
+		return values
+	}
+
+	flat := make([]any, ref.Len())
+	for i := range ref.Len() {
+		flat[i] = ref.Index(i).Interface()
+	}
+
+	return flat
+}
+
+// normalize 按字段类型校验并转换值：只接受标量
+func normalize(fieldType FieldType, value any) (any, error) {
+	if value == nil {
+		return nil, fmt.Errorf("值不能为空，请使用is null")
+	}
+
+	switch fieldType {
+	case FieldTypeString:
+		if val, ok := value.(string); ok {
+			return val, nil
+		}
+	case FieldTypeNumber:
+		switch val := value.(type) {
+		case json.Number:
+			return normalizeNumber(val.String())
+		case string:
+			return normalizeNumber(val)
+		}
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return value, nil
+		}
+	case FieldTypeBool:
+		switch val := value.(type) {
+		case bool:
+			return val, nil
+		case string:
+			if b, err := strconv.ParseBool(val); err == nil {
+				return b, nil
+			}
+		}
+	case FieldTypeTime:
+		switch val := value.(type) {
+		case time.Time:
+			return val, nil
+		case string:
+			for _, layout := range FinderTimeLayouts {
+				if t, err := time.ParseInLocation(layout, val, time.Local); err == nil {
+					return t, nil
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("不支持的字段类型：%s", fieldType)
+	}
+
+	return nil, fmt.Errorf("%v不是有效的%s", value, fieldType)
+}
+
+// normalizeNumber 转换数字字符串
+func normalizeNumber(val string) (any, error) {
+	if i, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(val, 64); err == nil {
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("%s不是有效的number", val)
+}
+
+// escapeLike 转义like通配符：使用!作为转义符，避免不同数据库对反斜杠的处理差异
+func escapeLike(val string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(val)
+}
+
+// TryQueryFromFinderQuery 按白名单校验并设置查询条件、排序和预加载：校验失败时不修改查询
+func (my *Finder) TryQueryFromFinderQuery(schema *FinderSchema, finderQuery *FinderQuery) error {
+	scope, err := schema.Scope(finderQuery)
+	if err != nil {
+		return err
+	}
+
+	my.DB = scope(my.DB)
+
+	return nil
+}
+
+// TryAutoFindFromFinderQuery 按白名单校验查询条件并查询：校验失败时不查询
+func (my *Finder) TryAutoFindFromFinderQuery(schema *FinderSchema, finderQuery *FinderQuery, page, size int, ret any) error {
+	if err := my.TryQueryFromFinderQuery(schema, finderQuery); err != nil {
+		return err
+	}
+
+	my.TryPagination(page, size).Find(ret)
+	if my.Total == -1 {
+		my.Total = 0
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package gormPool
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// newTestFinderSchema 测试用户表的查询白名单
+func newTestFinderSchema() *FinderSchema {
+	return FinderSchemaApp.New().
+		Field("name", "name", FieldTypeString).
+		Field("age", "age", FieldTypeNumber).
+		AddField("id", FinderField{Column: "id", Type: FieldTypeNumber, Sortable: true}).
+		AddField("secret", FinderField{Column: "name", Type: FieldTypeString, Filterable: true}).
+		AllowPreloads("Orders")
+}
+
+// testGroup 生成嵌套n层的分组
+func testGroup(depth int) *FinderGroup {
+	group := &FinderGroup{Conditions: []Condition{{Key: "age", Operator: ">", Values: []any{0}}}}
+	for range depth - 1 {
+		group = &FinderGroup{Groups: []FinderGroup{*group}}
+	}
+
+	return group
+}
+
+func TestFinderSchemaRejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		schema      *FinderSchema
+		finderQuery *FinderQuery
+		want        error
+	}{
+		{"未知字段", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "password", Operator: "=", Values: []any{"x"}}}}}, &FinderFieldErr},
+		{"不允许查询的字段", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "id", Operator: "=", Values: []any{1}}}}}, &FinderFieldErr},
+		{"SQL字段名", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "1=1 or name", Operator: "=", Values: []any{"x"}}}}}, &FinderFieldErr},
+		{"字段类型不支持的操作符", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "age", Operator: "like", Values: []any{"1"}}}}}, &FinderOperatorErr},
+		{"未知操作符", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "regexp", Values: []any{"x"}}}}}, &FinderOperatorErr},
+		{"raw", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "raw", Values: []any{"= 1"}}}}}, &FinderOperatorErr},
+		{"join", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "join", Values: []any{"x"}}}}}, &FinderOperatorErr},
+		{"自定义操作符", newTestFinderSchema().AddField("name", FinderField{Column: "name", Type: FieldTypeString, Filterable: true, Operators: []string{"="}}), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "like", Values: []any{"x"}}}}}, &FinderOperatorErr},
+		{"值数量", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "age", Operator: "between", Values: []any{1}}}}}, &FinderValueErr},
+		{"值类型", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "age", Operator: "=", Values: []any{"abc"}}}}}, &FinderValueErr},
+		{"非标量值", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "=", Values: []any{map[string]any{"x": 1}}}}}}, &FinderValueErr},
+		{"空值", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "=", Values: []any{nil}}}}}, &FinderValueErr},
+		{"不支持的分组操作", newTestFinderSchema(), &FinderQuery{Where: &FinderGroup{Option: "xor", Conditions: []Condition{{Key: "age", Operator: "=", Values: []any{1}}}}}, &FinderGroupErr},
+		{"超过最大嵌套层数", newTestFinderSchema().SetMaxDepth(2), &FinderQuery{Where: testGroup(3)}, &FinderGroupErr},
+		{"超过最大条件数量", newTestFinderSchema().SetMaxConditions(2), &FinderQuery{Where: &FinderGroup{
+			Conditions: []Condition{{Key: "age", Operator: ">", Values: []any{1}}, {Key: "age", Operator: "<", Values: []any{9}}},
+			Groups:     []FinderGroup{{Conditions: []Condition{{Key: "name", Operator: "=", Values: []any{"x"}}}}},
+		}}, &FinderGroupErr},
+		{"未知排序字段", newTestFinderSchema(), &FinderQuery{Orders: []string{"password desc"}}, &FinderFieldErr},
+		{"不允许排序的字段", newTestFinderSchema(), &FinderQuery{Orders: []string{"secret"}}, &FinderFieldErr},
+		{"排序方向", newTestFinderSchema(), &FinderQuery{Orders: []string{"age sideways"}}, &FinderFieldErr},
+		{"排序注入", newTestFinderSchema(), &FinderQuery{Orders: []string{"age desc, (select 1)"}}, &FinderFieldErr},
+		{"不允许的预加载", newTestFinderSchema(), &FinderQuery{Preloads: []string{"Secrets"}}, &FinderFieldErr},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scope, err := test.schema.Scope(test.finderQuery)
+			if !errors.Is(err, test.want) {
+				t.Fatalf("错误类型错误：%v", err)
+			}
+			if scope != nil {
+				t.Fatal("校验失败时不应返回scope")
+			}
+		})
+	}
+
+	// 边界内的嵌套层数和条件数量
+	if _, err := newTestFinderSchema().SetMaxDepth(3).Scope(&FinderQuery{Where: testGroup(3)}); err != nil {
+		t.Fatalf("最大嵌套层数内应通过：%v", err)
+	}
+	if _, err := newTestFinderSchema().SetMaxConditions(1).Scope(&FinderQuery{Where: testGroup(1)}); err != nil {
+		t.Fatalf("最大条件数量内应通过：%v", err)
+	}
+}
+
+func TestFinderSchemaQuery(t *testing.T) {
+	pool := newTestSqlitePool(t)
+	schema := newTestFinderSchema()
+
+	tests := []struct {
+		name  string
+		where *FinderGroup
+		sql   string
+		want  []string
+	}{
+		{"like转义%", &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "like", Values: []any{"%"}}}}, `WHERE (name LIKE "%!%%" ESCAPE '!')`, []string{"100%_off!"}},
+		{"like转义_", &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "like%", Values: []any{"10_"}}}}, `WHERE (name LIKE "10!_%" ESCAPE '!')`, nil},
+		{"like转义!", &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "%like", Values: []any{"f!"}}}}, `WHERE (name LIKE "%f!!" ESCAPE '!')`, []string{"100%_off!"}},
+		{"like字面匹配", &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "like", Values: []any{"%_o"}}}}, `WHERE (name LIKE "%!%!_o%" ESCAPE '!')`, []string{"100%_off!"}},
+		{"in", &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "in", Values: []any{"bob", "dave"}}}}, `WHERE (name IN ("bob","dave"))`, []string{"bob", "dave"}},
+		{"between", &FinderGroup{Conditions: []Condition{{Key: "age", Operator: "between", Values: []any{"30", 40}}}}, `WHERE (age BETWEEN 30 AND 40)`, []string{"bob", "carol"}},
+		{"is null", &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "is null"}}}, `WHERE (name IS NULL)`, nil},
+		{"嵌套and、or、not", &FinderGroup{
+			Option:     "or",
+			Conditions: []Condition{{Key: "age", Operator: ">=", Values: []any{50}}},
+			Groups: []FinderGroup{{
+				Conditions: []Condition{{Key: "name", Operator: "like%", Values: []any{"a"}}},
+				Groups:     []FinderGroup{{Option: "not", Conditions: []Condition{{Key: "age", Operator: "=", Values: []any{40}}, {Key: "name", Operator: "!=", Values: []any{"x"}}}}},
+			}},
+		}, `WHERE (age >= 50 OR (name LIKE "a%" ESCAPE '!' AND NOT (age = 40 AND name != "x")))`, []string{"alice", "dave", "100%_off!"}},
+		{"空分组", &FinderGroup{Groups: []FinderGroup{{Option: "or"}}}, "", []string{"alice", "bob", "carol", "dave", "100%_off!"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scope, err := schema.Scope(&FinderQuery{Where: test.where, Orders: []string{"id"}})
+			if err != nil {
+				t.Fatalf("校验失败：%v", err)
+			}
+
+			sql := pool.GetConn().ToSQL(func(db *gorm.DB) *gorm.DB {
+				return db.Model(&testUser{}).Scopes(scope).Find(&[]testUser{})
+			})
+			if test.sql == "" && strings.Contains(sql, "WHERE") || !strings.Contains(sql, test.sql+" ORDER BY id asc") {
+				t.Fatalf("SQL错误：%s", sql)
+			}
+
+			var users []testUser
+			if err = pool.GetConn().Model(&testUser{}).Scopes(scope).Find(&users).Error; err != nil {
+				t.Fatalf("查询失败：%v", err)
+			}
+			if got := names(users); !slices.Equal(got, test.want) {
+				t.Fatalf("查询结果错误：%v", got)
+			}
+		})
+	}
+}
+
+func TestFinderSchemaPreloads(t *testing.T) {
+	pool := newTestSqlitePool(t)
+
+	finderQuery := &FinderQuery{
+		Where:    &FinderGroup{Conditions: []Condition{{Key: "name", Operator: "=", Values: []any{"alice"}}}},
+		Preloads: []string{"Orders"},
+	}
+	scope, err := newTestFinderSchema().Scope(finderQuery)
+	if err != nil {
+		t.Fatalf("校验失败：%v", err)
+	}
+
+	// 校验后修改finderQuery不影响查询
+	finderQuery.Preloads[0] = "Secrets"
+	finderQuery.Preloads = append(finderQuery.Preloads, "Other")
+
+	var users []testUser
+	if err = pool.GetConn().Model(&testUser{}).Scopes(scope).Find(&users).Error; err != nil {
+		t.Fatalf("查询失败：%v", err)
+	}
+	if len(users) != 1 || len(users[0].Orders) != 2 {
+		t.Fatalf("预加载错误：%+v", users)
+	}
+}
+
+func TestFinderTryAutoFindFromFinderQuery(t *testing.T) {
+	pool := newTestSqlitePool(t)
+	schema := newTestFinderSchema()
+
+	var users []testUser
+	finder := FinderApp.New(pool.GetConn().Model(&testUser{}))
+	err := finder.TryAutoFindFromFinderQuery(schema, &FinderQuery{
+		Where:  &FinderGroup{Conditions: []Condition{{Key: "age", Operator: ">", Values: []any{"20"}}}},
+		Orders: []string{"age DESC"},
+	}, 2, 2, &users)
+	if err != nil {
+		t.Fatalf("查询失败：%v", err)
+	}
+	if finder.Total != 4 {
+		t.Fatalf("总数错误：%d", finder.Total)
+	}
+	if got := names(users); !slices.Equal(got, []string{"carol", "bob"}) {
+		t.Fatalf("查询结果错误：%v", got)
+	}
+
+	// 校验失败时不查询
+	users = nil
+	finder = FinderApp.New(pool.GetConn().Model(&testUser{}))
+	err = finder.TryAutoFindFromFinderQuery(schema, &FinderQuery{Orders: []string{"password"}}, 1, 10, &users)
+	if !errors.Is(err, &FinderFieldErr) {
+		t.Fatalf("应返回FinderFieldErr：%v", err)
+	}
+	if users != nil || finder.Total != -1 {
+		t.Fatalf("校验失败时不应查询：%v %d", users, finder.Total)
+	}
+
+	// FinderCondition转换后按白名单校验
+	and := "and"
+	finderQuery := schema.FromFinderCondition(&FinderCondition{
+		Queries: []struct {
+			Option     *string     `json:"option,omitempty"`
+			Conditions []Condition `json:"conditions,omitempty"`
+		}{{Option: &and, Conditions: []Condition{{Key: "name", Operator: "raw", Values: []any{"1=1"}}}}},
+	})
+	if _, err = schema.Scope(finderQuery); !errors.Is(err, &FinderOperatorErr) {
+		t.Fatalf("raw应被拒绝：%v", err)
+	}
+}
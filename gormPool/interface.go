@@ -1,11 +1,62 @@
 package gormPool
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type (
 	GormPool interface {
 		GetConn() *gorm.DB
 		getRws() *gorm.DB
+		Ping(ctx context.Context) error
 		Close() error
 	}
 )
+
+// newGormConfig 数据库配置
+func newGormConfig() *gorm.Config {
+	return &gorm.Config{
+		PrepareStmt:                              true,  // 预编译
+		CreateBatchSize:                          500,   // 批量操作
+		DisableForeignKeyConstraintWhenMigrating: true,  // 禁止自动创建外键
+		SkipDefaultTransaction:                   false, // 开启自动事务
+		QueryFields:                              true,  // 查询字段
+		AllowGlobalUpdate:                        false, // 不允许全局修改,必须带有条件
+	}
+}
+
+// setConnPool 设置链接池参数
+func setConnPool(conn *gorm.DB, common *Common) error {
+	sqlDb, err := conn.DB()
+	if err != nil {
+		return DbSettingErr.Wrap(err)
+	}
+
+	sqlDb.SetConnMaxIdleTime(time.Duration(common.MaxIdleTime) * time.Hour)
+	sqlDb.SetConnMaxLifetime(time.Duration(common.MaxLifetime) * time.Hour)
+	sqlDb.SetMaxIdleConns(common.MaxIdleConnections)
+	sqlDb.SetMaxOpenConns(common.MaxOpenConnections)
+
+	return nil
+}
+
+// ping 检查数据库链接
+func ping(ctx context.Context, conn *gorm.DB) error {
+	if conn == nil {
+		return DbPingErr.New("链接未初始化")
+	}
+
+	sqlDb, err := conn.DB()
+	if err != nil {
+		return DbPingErr.Wrap(err)
+	}
+
+	if err = sqlDb.PingContext(ctx); err != nil {
+		return DbPingErr.Wrap(err)
+	}
+
+	return nil
+}
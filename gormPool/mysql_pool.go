@@ -1,6 +1,7 @@
 package gormPool
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -22,6 +23,7 @@ type MySqlPool struct {
 	mainDsn   *Dsn
 	mainConn  *gorm.DB
 	dbSetting *DbSetting
+	rwsOnce   sync.Once
 }
 
 var (
@@ -31,14 +33,26 @@ var (
 	MySqlPoolApp   MySqlPool
 )
 
+func (*MySqlPool) New(dbSetting *DbSetting) (GormPool, error) {
+	pool, err := NewMySqlPool(dbSetting)
+	if err != nil {
+		return nil, err
+	}
+
+	return pool, nil
+}
+
 func (*MySqlPool) Once(dbSetting *DbSetting) GormPool { return OnceMySqlPool(dbSetting) }
 
-// OnceMySqlPool 单例化：mysql链接池
-//
-//go:fix 推荐使用：Once方法
-func OnceMySqlPool(dbSetting *DbSetting) GormPool {
-	mysqlPoolOnce.Do(func() {
-		mysqlPoolIns = &MySqlPool{
+// NewMySqlPool 实例化：mysql链接池；每次调用创建独立的链接，用于同时链接多个数据库
+func NewMySqlPool(dbSetting *DbSetting) (*MySqlPool, error) {
+	if dbSetting == nil || dbSetting.Common == nil || dbSetting.MySql == nil || dbSetting.MySql.Main == nil {
+		return nil, DbSettingErr.New("缺少mysql配置")
+	}
+
+	var (
+		err       error
+		mysqlPool = &MySqlPool{
 			username: dbSetting.MySql.Main.Username,
 			password: dbSetting.MySql.Main.Password,
 			host:     dbSetting.MySql.Main.Host,
@@ -50,15 +64,10 @@ func OnceMySqlPool(dbSetting *DbSetting) GormPool {
 
 			dbSetting: dbSetting,
 		}
-	})
-
-	var (
-		err      error
-		dbConfig *gorm.Config
 	)
 
 	// 配置主库
-	mysqlPoolIns.mainDsn = &Dsn{
+	mysqlPool.mainDsn = &Dsn{
 		Name: "main",
 		Content: fmt.Sprintf(
 			MySqlDsnFormat,
@@ -71,40 +80,43 @@ func OnceMySqlPool(dbSetting *DbSetting) GormPool {
 		),
 	}
 
-	// 数据库配置
-	dbConfig = &gorm.Config{
-		PrepareStmt:                              true,  // 预编译
-		CreateBatchSize:                          500,   // 批量操作
-		DisableForeignKeyConstraintWhenMigrating: true,  // 禁止自动创建外键
-		SkipDefaultTransaction:                   false, // 开启自动事务
-		QueryFields:                              true,  // 查询字段
-		AllowGlobalUpdate:                        false, // 不允许全局修改,必须带有条件
-	}
-
 	// 配置主库
-	mysqlPoolIns.mainConn, err = gorm.Open(mysql.Open(mysqlPoolIns.mainDsn.Content), dbConfig)
+	mysqlPool.mainConn, err = gorm.Open(mysql.Open(mysqlPool.mainDsn.Content), newGormConfig())
 	if err != nil {
-		panic(fmt.Sprintf("配置主库失败：%s", err.Error()))
+		return nil, fmt.Errorf("配置主库失败：%w", err)
 	}
 
-	mysqlPoolIns.mainConn = mysqlPoolIns.mainConn.Session(&gorm.Session{})
-	{
-		sqlDb, _ := mysqlPoolIns.mainConn.DB()
-		sqlDb.SetConnMaxIdleTime(time.Duration(mysqlPoolIns.dbSetting.Common.MaxIdleTime) * time.Hour)
-		sqlDb.SetConnMaxLifetime(time.Duration(mysqlPoolIns.dbSetting.Common.MaxLifetime) * time.Hour)
-		sqlDb.SetMaxIdleConns(mysqlPoolIns.dbSetting.Common.MaxIdleConnections)
-		sqlDb.SetMaxOpenConns(mysqlPoolIns.dbSetting.Common.MaxOpenConnections)
+	mysqlPool.mainConn = mysqlPool.mainConn.Session(&gorm.Session{})
+	if err = setConnPool(mysqlPool.mainConn, dbSetting.Common); err != nil {
+		return nil, err
 	}
 
+	return mysqlPool, nil
+}
+
+// OnceMySqlPool 单例化：mysql链接池
+//
+//go:fix 推荐使用：Once方法
+func OnceMySqlPool(dbSetting *DbSetting) GormPool {
+	mysqlPoolOnce.Do(func() {
+		var err error
+		if mysqlPoolIns, err = NewMySqlPool(dbSetting); err != nil {
+			panic(err.Error())
+		}
+	})
+
 	return mysqlPoolIns
 }
 
 // GetConn 获取主数据库链接
 func (my *MySqlPool) GetConn() *gorm.DB {
-	my.getRws()
+	my.rwsOnce.Do(func() { my.getRws() })
 	return my.mainConn
 }
 
+// Ping 检查数据库链接
+func (my *MySqlPool) Ping(ctx context.Context) error { return ping(ctx, my.mainConn) }
+
 // getRws 获取带有读写分离的数据库链接
 func (my *MySqlPool) getRws() *gorm.DB {
 	var (
@@ -1,6 +1,7 @@
 package gormPool
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -24,6 +25,7 @@ type PostgresPool struct {
 	mainConn     *gorm.DB
 	sources      map[string]*PostgresConnection
 	replicas     map[string]*PostgresConnection
+	rwsOnce      sync.Once
 }
 
 var (
@@ -33,14 +35,26 @@ var (
 	PostgresPoolApp   PostgresPool
 )
 
+func (*PostgresPool) New(dbSetting *DbSetting) (GormPool, error) {
+	pool, err := NewPostgresPool(dbSetting)
+	if err != nil {
+		return nil, err
+	}
+
+	return pool, nil
+}
+
 func (*PostgresPool) Once(dbSetting *DbSetting) GormPool { return OncePostgresPool(dbSetting) }
 
-// OncePostgresPool 单例化：postgres链接池
-//
-//go:fix 推荐使用Once方法
-func OncePostgresPool(dbSetting *DbSetting) GormPool {
-	postgresPoolOnce.Do(func() {
-		postgresPoolIns = &PostgresPool{
+// NewPostgresPool 实例化：postgres链接池；每次调用创建独立的链接，用于同时链接多个数据库
+func NewPostgresPool(dbSetting *DbSetting) (*PostgresPool, error) {
+	if dbSetting == nil || dbSetting.Common == nil || dbSetting.Postgres == nil || dbSetting.Postgres.Main == nil {
+		return nil, DbSettingErr.New("缺少postgres配置")
+	}
+
+	var (
+		err          error
+		postgresPool = &PostgresPool{
 			username:     dbSetting.Postgres.Main.Username,
 			password:     dbSetting.Postgres.Main.Password,
 			host:         dbSetting.Postgres.Main.Host,
@@ -51,15 +65,10 @@ func OncePostgresPool(dbSetting *DbSetting) GormPool {
 			maxIdleConns: dbSetting.Common.MaxIdleConnections,
 			maxOpenConns: dbSetting.Common.MaxOpenConnections,
 		}
-	})
-
-	var (
-		err      error
-		dbConfig *gorm.Config
 	)
 
 	// 配置主库
-	postgresPoolIns.mainDsn = &Dsn{
+	postgresPool.mainDsn = &Dsn{
 		Name: "main",
 		Content: fmt.Sprintf(
 			PostgresDsnFormat,
@@ -73,40 +82,43 @@ func OncePostgresPool(dbSetting *DbSetting) GormPool {
 		),
 	}
 
-	// 数据库配置
-	dbConfig = &gorm.Config{
-		PrepareStmt:                              true,  // 预编译
-		CreateBatchSize:                          500,   // 批量操作
-		DisableForeignKeyConstraintWhenMigrating: true,  // 禁止自动创建外键
-		SkipDefaultTransaction:                   false, // 开启自动事务
-		QueryFields:                              true,  // 查询字段
-		AllowGlobalUpdate:                        false, // 不允许全局修改,必须带有条件
-	}
-
 	// 配置主库
-	postgresPoolIns.mainConn, err = gorm.Open(postgres.Open(postgresPoolIns.mainDsn.Content), dbConfig)
+	postgresPool.mainConn, err = gorm.Open(postgres.Open(postgresPool.mainDsn.Content), newGormConfig())
 	if err != nil {
-		panic(fmt.Sprintf("配置数据库失败：%s", err.Error()))
+		return nil, fmt.Errorf("配置数据库失败：%w", err)
 	}
 
-	postgresPoolIns.mainConn = postgresPoolIns.mainConn.Session(&gorm.Session{})
-	{
-		sqlDb, _ := postgresPoolIns.mainConn.DB()
-		sqlDb.SetConnMaxIdleTime(time.Duration(postgresPoolIns.maxIdleTime) * time.Hour)
-		sqlDb.SetConnMaxLifetime(time.Duration(postgresPoolIns.maxLifetime) * time.Hour)
-		sqlDb.SetMaxIdleConns(postgresPoolIns.maxIdleConns)
-		sqlDb.SetMaxOpenConns(postgresPoolIns.maxOpenConns)
+	postgresPool.mainConn = postgresPool.mainConn.Session(&gorm.Session{})
+	if err = setConnPool(postgresPool.mainConn, dbSetting.Common); err != nil {
+		return nil, err
 	}
 
+	return postgresPool, nil
+}
+
+// OncePostgresPool 单例化：postgres链接池
+//
+//go:fix 推荐使用Once方法
+func OncePostgresPool(dbSetting *DbSetting) GormPool {
+	postgresPoolOnce.Do(func() {
+		var err error
+		if postgresPoolIns, err = NewPostgresPool(dbSetting); err != nil {
+			panic(err.Error())
+		}
+	})
+
 	return postgresPoolIns
 }
 
 // GetConn 获取主数据库链接
 func (my *PostgresPool) GetConn() *gorm.DB {
-	my.getRws()
+	my.rwsOnce.Do(func() { my.getRws() })
 	return my.mainConn
 }
 
+// Ping 检查数据库链接
+func (my *PostgresPool) Ping(ctx context.Context) error { return ping(ctx, my.mainConn) }
+
 // getRws 获取带有读写分离的数据库链接
 func (my *PostgresPool) getRws() *gorm.DB {
 	var (
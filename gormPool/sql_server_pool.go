@@ -1,6 +1,7 @@
 package gormPool
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -33,14 +34,26 @@ var (
 	SqlServerPoolApp   SqlServerPool
 )
 
+func (*SqlServerPool) New(dbSetting *DbSetting) (GormPool, error) {
+	pool, err := NewSqlServerPool(dbSetting)
+	if err != nil {
+		return nil, err
+	}
+
+	return pool, nil
+}
+
 func (*SqlServerPool) Once(dbSetting *DbSetting) GormPool { return OnceSqlServerPool(dbSetting) }
 
-// OnceSqlServerPool 单例化：sql server连接池
-//
-//go:fix 推荐使用Once方法
-func OnceSqlServerPool(dbSetting *DbSetting) GormPool {
-	sqlServerPoolOnce.Do(func() {
-		sqlServerPoolIns = &SqlServerPool{
+// NewSqlServerPool 实例化：sql server连接池；每次调用创建独立的链接，用于同时链接多个数据库
+func NewSqlServerPool(dbSetting *DbSetting) (*SqlServerPool, error) {
+	if dbSetting == nil || dbSetting.Common == nil || dbSetting.SqlServer == nil || dbSetting.SqlServer.Main == nil {
+		return nil, DbSettingErr.New("缺少sql server配置")
+	}
+
+	var (
+		err           error
+		sqlServerPool = &SqlServerPool{
 			username:     dbSetting.SqlServer.Main.Username,
 			password:     dbSetting.SqlServer.Main.Password,
 			host:         dbSetting.SqlServer.Main.Host,
@@ -51,15 +64,10 @@ func OnceSqlServerPool(dbSetting *DbSetting) GormPool {
 			maxIdleConns: dbSetting.Common.MaxIdleConnections,
 			maxOpenConns: dbSetting.Common.MaxOpenConnections,
 		}
-	})
-
-	var (
-		err      error
-		dbConfig *gorm.Config
 	)
 
 	// 配置主库
-	postgresPoolIns.mainDsn = &Dsn{
+	sqlServerPool.mainDsn = &Dsn{
 		Name: "main",
 		Content: fmt.Sprintf(
 			SqlServerDsnFormat,
@@ -71,37 +79,40 @@ func OnceSqlServerPool(dbSetting *DbSetting) GormPool {
 		),
 	}
 
-	// 数据库配置
-	dbConfig = &gorm.Config{
-		PrepareStmt:                              true,  // 预编译
-		CreateBatchSize:                          500,   // 批量操作
-		DisableForeignKeyConstraintWhenMigrating: true,  // 禁止自动创建外键
-		SkipDefaultTransaction:                   false, // 开启自动事务
-		QueryFields:                              true,  // 查询字段
-		AllowGlobalUpdate:                        false, // 不允许全局修改,必须带有条件
-	}
-
 	// 配置主库
-	sqlServerPoolIns.mainConn, err = gorm.Open(sqlserver.Open(sqlServerPoolIns.mainDsn.Content), dbConfig)
+	sqlServerPool.mainConn, err = gorm.Open(sqlserver.Open(sqlServerPool.mainDsn.Content), newGormConfig())
 	if err != nil {
-		panic(fmt.Sprintf("配置数据库失败：%s", err.Error()))
+		return nil, fmt.Errorf("配置数据库失败：%w", err)
 	}
 
-	sqlServerPoolIns.mainConn = sqlServerPoolIns.mainConn.Session(&gorm.Session{})
-	{
-		sqlDb, _ := sqlServerPoolIns.mainConn.DB()
-		sqlDb.SetConnMaxIdleTime(time.Duration(sqlServerPoolIns.maxIdleTime) * time.Hour)
-		sqlDb.SetConnMaxLifetime(time.Duration(sqlServerPoolIns.maxLifetime) * time.Hour)
-		sqlDb.SetMaxIdleConns(sqlServerPoolIns.maxIdleConns)
-		sqlDb.SetMaxOpenConns(sqlServerPoolIns.maxOpenConns)
+	sqlServerPool.mainConn = sqlServerPool.mainConn.Session(&gorm.Session{})
+	if err = setConnPool(sqlServerPool.mainConn, dbSetting.Common); err != nil {
+		return nil, err
 	}
 
+	return sqlServerPool, nil
+}
+
+// OnceSqlServerPool 单例化：sql server连接池
+//
+//go:fix 推荐使用Once方法
+func OnceSqlServerPool(dbSetting *DbSetting) GormPool {
+	sqlServerPoolOnce.Do(func() {
+		var err error
+		if sqlServerPoolIns, err = NewSqlServerPool(dbSetting); err != nil {
+			panic(err.Error())
+		}
+	})
+
 	return sqlServerPoolIns
 }
 
 // GetConn 获取主数据库链接
 func (my *SqlServerPool) GetConn() *gorm.DB { return my.mainConn }
 
+// Ping 检查数据库链接
+func (my *SqlServerPool) Ping(ctx context.Context) error { return ping(ctx, my.mainConn) }
+
 // getRws 获取带有读写分离的数据库链接
 func (my *SqlServerPool) getRws() *gorm.DB {
 	var (
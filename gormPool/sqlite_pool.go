@@ -0,0 +1,144 @@
+package gormPool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+type SqlitePool struct {
+	database string
+	memory   bool
+	mainDsn  *Dsn
+	mainConn *gorm.DB
+}
+
+var (
+	sqlitePoolIns    *SqlitePool
+	sqlitePoolOnce   sync.Once
+	sqliteMemorySeq  atomic.Uint64
+	SqliteDsnFormat  = "file:%s?%s"
+	SqliteMemoryMode = "mode=memory&cache=shared"
+	SqlitePoolApp    SqlitePool
+)
+
+func (*SqlitePool) New(dbSetting *DbSetting) (GormPool, error) {
+	pool, err := NewSqlitePool(dbSetting)
+	if err != nil {
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+func (*SqlitePool) Once(dbSetting *DbSetting) GormPool { return OnceSqlitePool(dbSetting) }
+
+// NewSqlitePool 实例化：sqlite链接池；内存模式下同名的链接池共享数据库，未设置名称时每次创建独立的数据库，所有链接关闭后数据丢失
+func NewSqlitePool(dbSetting *DbSetting) (*SqlitePool, error) {
+	if dbSetting == nil || dbSetting.Sqlite == nil {
+		return nil, DbSettingErr.New("缺少sqlite配置")
+	}
+	if !dbSetting.Sqlite.Memory && dbSetting.Sqlite.Database == "" {
+		return nil, DbSettingErr.New("缺少sqlite数据库文件")
+	}
+
+	var (
+		err        error
+		params     []string
+		sqlitePool = &SqlitePool{
+			database: dbSetting.Sqlite.Database,
+			memory:   dbSetting.Sqlite.Memory,
+		}
+	)
+
+	if sqlitePool.memory {
+		if sqlitePool.database == "" {
+			sqlitePool.database = fmt.Sprintf("memory-%d", sqliteMemorySeq.Add(1))
+		}
+		params = append(params, SqliteMemoryMode)
+	}
+	if dbSetting.Sqlite.Params != "" {
+		params = append(params, dbSetting.Sqlite.Params)
+	}
+
+	// 配置主库
+	sqlitePool.mainDsn = &Dsn{
+		Name:    "main",
+		Content: fmt.Sprintf(SqliteDsnFormat, sqlitePool.database, strings.Join(params, "&")),
+	}
+
+	// 配置主库
+	sqlitePool.mainConn, err = gorm.Open(sqlite.Open(sqlitePool.mainDsn.Content), newGormConfig())
+	if err != nil {
+		return nil, fmt.Errorf("配置数据库失败：%w", err)
+	}
+
+	sqlitePool.mainConn = sqlitePool.mainConn.Session(&gorm.Session{})
+	if dbSetting.Common != nil {
+		if err = setConnPool(sqlitePool.mainConn, dbSetting.Common); err != nil {
+			return nil, err
+		}
+	}
+
+	// 内存模式：至少保留一个空闲链接且不过期，避免所有链接关闭后数据丢失
+	if sqlitePool.memory {
+		sqlDb, err := sqlitePool.mainConn.DB()
+		if err != nil {
+			return nil, DbSettingErr.Wrap(err)
+		}
+
+		maxIdleConns := 2
+		if dbSetting.Common != nil {
+			maxIdleConns = max(dbSetting.Common.MaxIdleConnections, 1)
+		}
+		sqlDb.SetMaxIdleConns(maxIdleConns)
+		sqlDb.SetConnMaxIdleTime(0)
+		sqlDb.SetConnMaxLifetime(0)
+	}
+
+	return sqlitePool, nil
+}
+
+// OnceSqlitePool 单例化：sqlite链接池
+//
+//go:fix 推荐使用：Once方法
+func OnceSqlitePool(dbSetting *DbSetting) GormPool {
+	sqlitePoolOnce.Do(func() {
+		var err error
+		if sqlitePoolIns, err = NewSqlitePool(dbSetting); err != nil {
+			panic(err.Error())
+		}
+	})
+
+	return sqlitePoolIns
+}
+
+// GetConn 获取主数据库链接
+func (my *SqlitePool) GetConn() *gorm.DB { return my.mainConn }
+
+// getRws 获取带有读写分离的数据库链接：sqlite不支持读写分离，返回主数据库链接
+func (my *SqlitePool) getRws() *gorm.DB { return my.mainConn }
+
+// Ping 检查数据库链接
+func (my *SqlitePool) Ping(ctx context.Context) error { return ping(ctx, my.mainConn) }
+
+// Close 关闭数据库链接
+func (my *SqlitePool) Close() error {
+	if my.mainConn != nil {
+		db, err := my.mainConn.DB()
+		if err != nil {
+			return fmt.Errorf("关闭数据库链接失败：获取数据库链接失败 %s", err.Error())
+		}
+		err = db.Close()
+		if err != nil {
+			return fmt.Errorf("关闭数据库连接失败 %s", err.Error())
+		}
+	}
+
+	return nil
+}
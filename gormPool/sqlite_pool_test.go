@@ -0,0 +1,368 @@
+package gormPool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type (
+	testUser struct {
+		Id     uint
+		Name   string
+		Age    int
+		Orders []testOrder `gorm:"foreignKey:UserId"`
+	}
+
+	testOrder struct {
+		Id     uint
+		UserId uint
+		Amount int
+	}
+)
+
+// newTestSqlitePool 创建内存模式的sqlite链接池并写入测试数据：每次调用使用独立的数据库
+func newTestSqlitePool(t *testing.T) GormPool {
+	t.Helper()
+
+	pool, err := SqlitePoolApp.New(&DbSetting{Sqlite: &SqliteSetting{Memory: true}})
+	if err != nil {
+		t.Fatalf("创建sqlite链接池失败：%v", err)
+	}
+	t.Cleanup(func() { _ = pool.Close() })
+
+	db := pool.GetConn()
+	if err = db.AutoMigrate(&testUser{}, &testOrder{}); err != nil {
+		t.Fatalf("创建表失败：%v", err)
+	}
+
+	users := []testUser{
+		{Name: "alice", Age: 20, Orders: []testOrder{{Amount: 10}, {Amount: 20}}},
+		{Name: "bob", Age: 30},
+		{Name: "carol", Age: 40, Orders: []testOrder{{Amount: 30}}},
+		{Name: "dave", Age: 50},
+		{Name: "100%_off!", Age: 60},
+	}
+	if err = db.Create(&users).Error; err != nil {
+		t.Fatalf("写入测试数据失败：%v", err)
+	}
+
+	return pool
+}
+
+// names 获取用户名列表
+func names(users []testUser) []string {
+	ret := make([]string, len(users))
+	for idx, user := range users {
+		ret[idx] = user.Name
+	}
+
+	return ret
+}
+
+func TestSqlitePoolMemory(t *testing.T) {
+	pool := newTestSqlitePool(t)
+
+	if err := pool.Ping(context.Background()); err != nil {
+		t.Fatalf("检查链接失败：%v", err)
+	}
+
+	// 未设置名称时每次创建独立的数据库
+	other, err := SqlitePoolApp.New(&DbSetting{Sqlite: &SqliteSetting{Memory: true}})
+	if err != nil {
+		t.Fatalf("创建sqlite链接池失败：%v", err)
+	}
+	defer func() { _ = other.Close() }()
+	if other.GetConn().Migrator().HasTable(&testUser{}) {
+		t.Fatal("不同的内存数据库不应共享数据")
+	}
+
+	// 同名的链接池共享数据库
+	first, err := SqlitePoolApp.New(&DbSetting{Sqlite: &SqliteSetting{Memory: true, Database: "shared"}})
+	if err != nil {
+		t.Fatalf("创建sqlite链接池失败：%v", err)
+	}
+	defer func() { _ = first.Close() }()
+	second, err := SqlitePoolApp.New(&DbSetting{Sqlite: &SqliteSetting{Memory: true, Database: "shared"}})
+	if err != nil {
+		t.Fatalf("创建sqlite链接池失败：%v", err)
+	}
+	defer func() { _ = second.Close() }()
+
+	if err = first.GetConn().AutoMigrate(&testUser{}); err != nil {
+		t.Fatalf("创建表失败：%v", err)
+	}
+	if !second.GetConn().Migrator().HasTable(&testUser{}) {
+		t.Fatal("同名的内存数据库应共享数据")
+	}
+
+	if _, err = SqlitePoolApp.New(&DbSetting{Sqlite: &SqliteSetting{}}); err == nil {
+		t.Fatal("缺少数据库文件时应返回错误")
+	}
+}
+
+func TestSqlitePoolParams(t *testing.T) {
+	pool, err := SqlitePoolApp.New(&DbSetting{Sqlite: &SqliteSetting{Memory: true, Params: "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"}})
+	if err != nil {
+		t.Fatalf("创建sqlite链接池失败：%v", err)
+	}
+	defer func() { _ = pool.Close() }()
+
+	var foreignKeys, busyTimeout int
+	if err = pool.GetConn().Raw("PRAGMA foreign_keys").Scan(&foreignKeys).Error; err != nil {
+		t.Fatalf("查询foreign_keys失败：%v", err)
+	}
+	if err = pool.GetConn().Raw("PRAGMA busy_timeout").Scan(&busyTimeout).Error; err != nil {
+		t.Fatalf("查询busy_timeout失败：%v", err)
+	}
+	if foreignKeys != 1 || busyTimeout != 5000 {
+		t.Fatalf("链接参数未生效：foreign_keys=%d busy_timeout=%d", foreignKeys, busyTimeout)
+	}
+
+	// 外键约束生效：引用不存在的记录时写入失败
+	if err = pool.GetConn().Exec("CREATE TABLE parents (id INTEGER PRIMARY KEY)").Error; err != nil {
+		t.Fatalf("创建表失败：%v", err)
+	}
+	if err = pool.GetConn().Exec("CREATE TABLE children (id INTEGER PRIMARY KEY, parent_id INTEGER REFERENCES parents(id))").Error; err != nil {
+		t.Fatalf("创建表失败：%v", err)
+	}
+	if err = pool.GetConn().Exec("INSERT INTO children (parent_id) VALUES (1)").Error; err == nil {
+		t.Fatal("外键约束应生效")
+	}
+}
+
+func TestFinder(t *testing.T) {
+	pool := newTestSqlitePool(t)
+
+	var users []testUser
+	finder := FinderApp.New(pool.GetConn().Model(&testUser{})).
+		When(true, "age >= ?", 30).
+		When(false, "age < ?", 0).
+		WhenIn(true, "name", []string{"bob", "carol", "dave"}).
+		TryPagination(1, 2).
+		TryOrder("age desc").
+		Find(&users, "Orders")
+
+	if finder.Total != 3 {
+		t.Fatalf("总数错误：%d", finder.Total)
+	}
+	if got := names(users); !slices.Equal(got, []string{"dave", "carol"}) {
+		t.Fatalf("查询结果错误：%v", got)
+	}
+	if len(users[1].Orders) != 1 || users[1].Orders[0].Amount != 30 {
+		t.Fatalf("预加载错误：%+v", users[1].Orders)
+	}
+}
+
+func TestFinderTryQueryFromMap(t *testing.T) {
+	pool := newTestSqlitePool(t)
+
+	tests := []struct {
+		name    string
+		queries map[string][]any
+		want    []string
+	}{
+		{"比较", map[string][]any{"age": {">", 30}}, []string{"carol", "dave", "100%_off!"}},
+		{"in", map[string][]any{"name": {"in", []string{"alice", "bob"}}}, []string{"alice", "bob"}},
+		{"not in", map[string][]any{"name": {"not in", []string{"alice", "bob", "100%_off!"}}}, []string{"carol", "dave"}},
+		{"between", map[string][]any{"age": {"between", 20, 30}}, []string{"alice", "bob"}},
+		{"like", map[string][]any{"name": {"like", "aro"}}, []string{"carol"}},
+		{"like%", map[string][]any{"name": {"like%", "da"}}, []string{"dave"}},
+		{"%like", map[string][]any{"name": {"%like", "ce"}}, []string{"alice"}},
+		{"多个条件", map[string][]any{"age": {">=", 30}, "name": {"!=", "dave"}}, []string{"bob", "carol", "100%_off!"}},
+		{"忽略无效条件", map[string][]any{"age": {"="}, "name": {1, 2}}, []string{"alice", "bob", "carol", "dave", "100%_off!"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var users []testUser
+			finder := FinderApp.New(pool.GetConn().Model(&testUser{})).TryAutoFindFromMap(test.queries, nil, []string{"id"}, 0, 0, &users)
+
+			if finder.DB.Error != nil {
+				t.Fatalf("查询失败：%v", finder.DB.Error)
+			}
+			if got := names(users); !slices.Equal(got, test.want) {
+				t.Fatalf("查询结果错误：%v", got)
+			}
+		})
+	}
+
+	t.Run("别名", func(t *testing.T) {
+		var users []testUser
+		FinderApp.New(pool.GetConn().Model(&testUser{})).
+			TryQueryFromMap(map[string][]any{
+				"test_users": {"alias", "u"},
+				"u.age":      {">=", 40},
+			}).
+			TryOrder("u.id").
+			Ex(func(db *gorm.DB) {
+				db.Joins("join test_orders o on o.user_id = u.id and o.amount > ?", 15).Distinct("u.*")
+			}).
+			Find(&users)
+
+		if got := names(users); !slices.Equal(got, []string{"carol"}) {
+			t.Fatalf("查询结果错误：%v", got)
+		}
+	})
+
+	rejected := []struct {
+		name    string
+		queries map[string][]any
+		want    error
+	}{
+		{"raw", map[string][]any{"age % 20 = ?": {"raw", 0}}, &FinderFieldErr},
+		{"raw普通标识符", map[string][]any{"age": {"raw", "> ?", 20}}, &FinderOperatorErr},
+		{"join", map[string][]any{"join test_orders o on o.user_id = test_users.id": {"join", 15}}, &FinderFieldErr},
+		{"join普通标识符", map[string][]any{"test_orders": {"join", "on 1=1"}}, &FinderOperatorErr},
+		{"非普通标识符", map[string][]any{"1=1 or name": {"=", "x"}}, &FinderFieldErr},
+		{"别名注入", map[string][]any{"test_users": {"alias", "u where 1=1"}}, &FinderFieldErr},
+	}
+
+	for _, test := range rejected {
+		t.Run(test.name, func(t *testing.T) {
+			var users []testUser
+			finder := FinderApp.New(pool.GetConn().Model(&testUser{})).TryQueryFromMap(test.queries).Find(&users)
+
+			if !errors.Is(finder.DB.Error, test.want) {
+				t.Fatalf("错误类型错误：%v", finder.DB.Error)
+			}
+			if len(users) != 0 {
+				t.Fatalf("校验失败时不应查询：%v", names(users))
+			}
+		})
+	}
+}
+
+func TestFinderTryQueryFromFinderCondition(t *testing.T) {
+	pool := newTestSqlitePool(t)
+
+	var (
+		and, or = "and", "or"
+		table   = "test_users"
+	)
+
+	finderCondition := &FinderCondition{
+		Table: &table,
+		Queries: []struct {
+			Option     *string     `json:"option,omitempty"`
+			Conditions []Condition `json:"conditions,omitempty"`
+		}{
+			{Option: &and, Conditions: []Condition{{Key: "age", Operator: ">=", Values: []any{20}}, {Key: "age", Operator: "<=", Values: []any{40}}}},
+			{Option: &or, Conditions: []Condition{{Key: "name", Operator: "like%", Values: []any{"da"}}}},
+		},
+		Orders:   []string{"age desc"},
+		Preloads: []string{"Orders"},
+	}
+
+	var users []testUser
+	finder := FinderApp.New(pool.GetConn().Model(&testUser{})).TryAutoFindFromFinderCondition(finderCondition, 1, 3, &users)
+
+	if finder.DB.Error != nil {
+		t.Fatalf("查询失败：%v", finder.DB.Error)
+	}
+	if finder.Total != 4 {
+		t.Fatalf("总数错误：%d", finder.Total)
+	}
+	if got := names(users); !slices.Equal(got, []string{"dave", "carol", "bob"}) {
+		t.Fatalf("查询结果错误：%v", got)
+	}
+	if len(users[1].Orders) != 1 {
+		t.Fatalf("预加载错误：%+v", users[1].Orders)
+	}
+
+	tests := []struct {
+		name       string
+		conditions []Condition
+		want       []string
+	}{
+		{"in", []Condition{{Key: "name", Operator: "in", Values: []any{[]string{"bob", "dave"}}}}, []string{"bob", "dave"}},
+		{"between", []Condition{{Key: "age", Operator: "not between", Values: []any{20, 50}}}, []string{"100%_off!"}},
+		{"like", []Condition{{Key: "name", Operator: "like", Values: []any{"o"}}}, []string{"bob", "carol", "100%_off!"}},
+		{"%like", []Condition{{Key: "name", Operator: "%like", Values: []any{"ol"}}}, []string{"carol"}},
+		{"忽略空值", []Condition{{Key: "age", Operator: "="}}, []string{"alice", "bob", "carol", "dave", "100%_off!"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var users []testUser
+			FinderApp.New(pool.GetConn().Model(&testUser{})).
+				TryQueryFromFinderCondition(&FinderCondition{
+					Queries: []struct {
+						Option     *string     `json:"option,omitempty"`
+						Conditions []Condition `json:"conditions,omitempty"`
+					}{{Conditions: test.conditions}},
+					Orders: []string{"id"},
+				}).
+				Find(&users)
+
+			if got := names(users); !slices.Equal(got, test.want) {
+				t.Fatalf("查询结果错误：%v", got)
+			}
+		})
+	}
+
+	rejected := []struct {
+		name       string
+		table      string
+		conditions []Condition
+		want       error
+	}{
+		{"raw", "", []Condition{{Key: "age > ? and age < ?", Operator: "raw", Values: []any{20, 50}}}, &FinderFieldErr},
+		{"raw普通标识符", "", []Condition{{Key: "age", Operator: "raw", Values: []any{"> 20"}}}, &FinderOperatorErr},
+		{"join", "", []Condition{{Key: "test_orders", Operator: "left join", Values: []any{"on 1=1"}}}, &FinderOperatorErr},
+		{"非普通标识符", "", []Condition{{Key: "1=1 or name", Operator: "=", Values: []any{"x"}}}, &FinderFieldErr},
+		{"表名注入", "test_users where 1=1", nil, &FinderFieldErr},
+	}
+
+	for _, test := range rejected {
+		t.Run(test.name, func(t *testing.T) {
+			var users []testUser
+			finder := FinderApp.New(pool.GetConn().Model(&testUser{})).
+				TryQueryFromFinderCondition(&FinderCondition{
+					Table: &test.table,
+					Queries: []struct {
+						Option     *string     `json:"option,omitempty"`
+						Conditions []Condition `json:"conditions,omitempty"`
+					}{{Conditions: test.conditions}},
+				}).
+				Find(&users)
+
+			if !errors.Is(finder.DB.Error, test.want) {
+				t.Fatalf("错误类型错误：%v", finder.DB.Error)
+			}
+			if len(users) != 0 {
+				t.Fatalf("校验失败时不应查询：%v", names(users))
+			}
+		})
+	}
+
+	t.Run("not对每个条件单独取反", func(t *testing.T) {
+		not := "not"
+		finderCondition := &FinderCondition{
+			Queries: []struct {
+				Option     *string     `json:"option,omitempty"`
+				Conditions []Condition `json:"conditions,omitempty"`
+			}{{Option: &not, Conditions: []Condition{{Key: "name", Operator: "=", Values: []any{"alice"}}, {Key: "age", Operator: "=", Values: []any{30}}}}},
+			Orders: []string{"id"},
+		}
+		want := []string{"carol", "dave", "100%_off!"}
+
+		var legacy []testUser
+		FinderApp.New(pool.GetConn().Model(&testUser{})).TryQueryFromFinderCondition(finderCondition).Find(&legacy)
+		if got := names(legacy); !slices.Equal(got, want) {
+			t.Fatalf("查询结果错误：%v", got)
+		}
+
+		// 转换为FinderQuery后语义不变
+		var users []testUser
+		if err := FinderApp.New(pool.GetConn().Model(&testUser{})).TryAutoFindFromFinderQuery(newTestFinderSchema(), newTestFinderSchema().FromFinderCondition(finderCondition), 0, 0, &users); err != nil {
+			t.Fatalf("查询失败：%v", err)
+		}
+		if got := names(users); !slices.Equal(got, want) {
+			t.Fatalf("转换后查询结果错误：%v", got)
+		}
+	})
+}
@@ -21,6 +21,16 @@ type (
 	SetXmlBodyError      struct{ myError.MyError }
 	SetJsonBodyError     struct{ myError.MyError }
 	WriteResponseError   struct{ myError.MyError }
+	RequestCanceledError struct{ myError.MyError }
+	RequestTimeoutError  struct{ myError.MyError }
+	RequestNetworkError  struct{ myError.MyError }
+	InterceptError       struct{ myError.MyError }
+	DownloadError        struct{ myError.MyError }
+	ChecksumError        struct{ myError.MyError }
+	StreamError          struct{ myError.MyError }
+	DecodeError          struct{ myError.MyError }
+	MockError            struct{ myError.MyError }
+	RecordError          struct{ myError.MyError }
 )
 
 var (
@@ -35,6 +45,16 @@ var (
 	SetXmlBodyErr      SetXmlBodyError
 	SetJsonBodyErr     SetJsonBodyError
 	WriteResponseErr   WriteResponseError
+	RequestCanceledErr RequestCanceledError
+	RequestTimeoutErr  RequestTimeoutError
+	RequestNetworkErr  RequestNetworkError
+	InterceptErr       InterceptError
+	DownloadErr        DownloadError
+	ChecksumErr        ChecksumError
+	StreamErr          StreamError
+	DecodeErr          DecodeError
+	MockErr            MockError
+	RecordErr          RecordError
 )
 
 func (*ReadResponseError) New(msg string) myError.IMyError {
@@ -228,3 +248,169 @@ func (my *WriteResponseError) Error() string { return my.MyError.Msg }
 func (my *WriteResponseError) Is(target error) bool {
 	return reflect.DeepEqual(target, &WriteResponseErr)
 }
+
+func (*RequestCanceledError) New(msg string) myError.IMyError {
+	return &RequestCanceledError{MyError: myError.MyError{Msg: array.New([]string{"请求被取消", msg}).JoinWithoutEmpty("：")}}
+}
+
+func (*RequestCanceledError) Wrap(err error) myError.IMyError {
+	return &RequestCanceledError{MyError: myError.MyError{Msg: fmt.Errorf("请求被取消"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*RequestCanceledError) Panic() myError.IMyError {
+	return &RequestCanceledError{MyError: myError.MyError{Msg: "请求被取消"}}
+}
+
+func (my *RequestCanceledError) Error() string { return my.MyError.Msg }
+
+func (my *RequestCanceledError) Is(target error) bool {
+	return reflect.DeepEqual(target, &RequestCanceledErr)
+}
+
+func (*RequestTimeoutError) New(msg string) myError.IMyError {
+	return &RequestTimeoutError{MyError: myError.MyError{Msg: array.New([]string{"请求超时", msg}).JoinWithoutEmpty("：")}}
+}
+
+func (*RequestTimeoutError) Wrap(err error) myError.IMyError {
+	return &RequestTimeoutError{MyError: myError.MyError{Msg: fmt.Errorf("请求超时"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*RequestTimeoutError) Panic() myError.IMyError {
+	return &RequestTimeoutError{MyError: myError.MyError{Msg: "请求超时"}}
+}
+
+func (my *RequestTimeoutError) Error() string { return my.MyError.Msg }
+
+func (my *RequestTimeoutError) Is(target error) bool {
+	return reflect.DeepEqual(target, &RequestTimeoutErr)
+}
+
+func (*RequestNetworkError) New(msg string) myError.IMyError {
+	return &RequestNetworkError{MyError: myError.MyError{Msg: array.New([]string{"网络错误", msg}).JoinWithoutEmpty("：")}}
+}
+
+func (*RequestNetworkError) Wrap(err error) myError.IMyError {
+	return &RequestNetworkError{MyError: myError.MyError{Msg: fmt.Errorf("网络错误"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*RequestNetworkError) Panic() myError.IMyError {
+	return &RequestNetworkError{MyError: myError.MyError{Msg: "网络错误"}}
+}
+
+func (my *RequestNetworkError) Error() string { return my.MyError.Msg }
+
+func (my *RequestNetworkError) Is(target error) bool {
+	return reflect.DeepEqual(target, &RequestNetworkErr)
+}
+
+func (*InterceptError) New(msg string) myError.IMyError {
+	return &InterceptError{MyError: myError.MyError{Msg: array.New([]string{"拦截器中断请求", msg}).JoinWithoutEmpty("：")}}
+}
+
+func (*InterceptError) Wrap(err error) myError.IMyError {
+	return &InterceptError{MyError: myError.MyError{Msg: fmt.Errorf("拦截器中断请求"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*InterceptError) Panic() myError.IMyError {
+	return &InterceptError{MyError: myError.MyError{Msg: "拦截器中断请求"}}
+}
+
+func (my *InterceptError) Error() string { return my.MyError.Msg }
+
+func (my *InterceptError) Is(target error) bool { return reflect.DeepEqual(target, &InterceptErr) }
+
+func (*DownloadError) New(msg string) myError.IMyError {
+	return &DownloadError{MyError: myError.MyError{Msg: array.New([]string{"下载失败", msg}).JoinWithoutEmpty("：")}}
+}
+
+func (*DownloadError) Wrap(err error) myError.IMyError {
+	return &DownloadError{MyError: myError.MyError{Msg: fmt.Errorf("下载失败"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*DownloadError) Panic() myError.IMyError {
+	return &DownloadError{MyError: myError.MyError{Msg: "下载失败"}}
+}
+
+func (my *DownloadError) Error() string { return my.MyError.Msg }
+
+func (my *DownloadError) Is(target error) bool { return reflect.DeepEqual(target, &DownloadErr) }
+
+func (*ChecksumError) New(msg string) myError.IMyError {
+	return &ChecksumError{MyError: myError.MyError{Msg: array.New([]string{"文件校验失败", msg}).JoinWithoutEmpty("：")}}
+}
+
+func (*ChecksumError) Wrap(err error) myError.IMyError {
+	return &ChecksumError{MyError: myError.MyError{Msg: fmt.Errorf("文件校验失败"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*ChecksumError) Panic() myError.IMyError {
+	return &ChecksumError{MyError: myError.MyError{Msg: "文件校验失败"}}
+}
+
+func (my *ChecksumError) Error() string { return my.MyError.Msg }
+
+func (my *ChecksumError) Is(target error) bool { return reflect.DeepEqual(target, &ChecksumErr) }
+
+func (*StreamError) New(msg string) myError.IMyError {
+	return &StreamError{MyError: myError.MyError{Msg: array.New([]string{"读取流式响应失败", msg}).JoinWithoutEmpty("：")}}
+}
+
+func (*StreamError) Wrap(err error) myError.IMyError {
+	return &StreamError{MyError: myError.MyError{Msg: fmt.Errorf("读取流式响应失败"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*StreamError) Panic() myError.IMyError {
+	return &StreamError{MyError: myError.MyError{Msg: "读取流式响应失败"}}
+}
+
+func (my *StreamError) Error() string { return my.MyError.Msg }
+
+func (my *StreamError) Is(target error) bool { return reflect.DeepEqual(target, &StreamErr) }
+
+func (*DecodeError) New(msg string) myError.IMyError {
+	return &DecodeError{MyError: myError.MyError{Msg: array.New([]string{"解析响应体失败", msg}).JoinWithoutEmpty("：")}}
+}
+
+func (*DecodeError) Wrap(err error) myError.IMyError {
+	return &DecodeError{MyError: myError.MyError{Msg: fmt.Errorf("解析响应体失败"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*DecodeError) Panic() myError.IMyError {
+	return &DecodeError{MyError: myError.MyError{Msg: "解析响应体失败"}}
+}
+
+func (my *DecodeError) Error() string { return my.MyError.Msg }
+
+func (my *DecodeError) Is(target error) bool { return reflect.DeepEqual(target, &DecodeErr) }
+
+func (*MockError) New(msg string) myError.IMyError {
+	return &MockError{MyError: myError.MyError{Msg: array.New([]string{"模拟请求失败", msg}).JoinWithoutEmpty("：")}}
+}
+
+func (*MockError) Wrap(err error) myError.IMyError {
+	return &MockError{MyError: myError.MyError{Msg: fmt.Errorf("模拟请求失败"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*MockError) Panic() myError.IMyError {
+	return &MockError{MyError: myError.MyError{Msg: "模拟请求失败"}}
+}
+
+func (my *MockError) Error() string { return my.MyError.Msg }
+
+func (my *MockError) Is(target error) bool { return reflect.DeepEqual(target, &MockErr) }
+
+func (*RecordError) New(msg string) myError.IMyError {
+	return &RecordError{MyError: myError.MyError{Msg: array.New([]string{"录制回放失败", msg}).JoinWithoutEmpty("：")}}
+}
+
+func (*RecordError) Wrap(err error) myError.IMyError {
+	return &RecordError{MyError: myError.MyError{Msg: fmt.Errorf("录制回放失败"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*RecordError) Panic() myError.IMyError {
+	return &RecordError{MyError: myError.MyError{Msg: "录制回放失败"}}
+}
+
+func (my *RecordError) Error() string { return my.MyError.Msg }
+
+func (my *RecordError) Is(target error) bool { return reflect.DeepEqual(target, &RecordErr) }
@@ -2,19 +2,22 @@ package httpClient
 
 import (
 	"bytes"
-	"crypto/tls"
-	"crypto/x509"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/jericho-yu/aid/operation"
 	"github.com/jericho-yu/aid/str"
 	jsonIter "github.com/json-iterator/go"
 )
@@ -35,10 +38,23 @@ type (
 		isReady            bool
 		cert               []byte
 		transport          *http.Transport
-		timeoutSecond      int64
+		ctx                context.Context
+		timeout            time.Duration // 单次请求总超时
+		dialTimeout        time.Duration // 建立链接超时
+		tlsTimeout         time.Duration // TLS握手超时
+		headerTimeout      time.Duration // 等待响应头超时
+		readBodyTimeout    time.Duration // 读取响应体超时
+		session            *HttpSession
+		roundTripper       http.RoundTripper
+		interceptors       []Interceptor
+		retryPolicy        *RetryPolicy
+		attempts           []*SendAttempt
 	}
 )
 
+// errReadBodyTimeout 读取响应体超时（用于区分取消原因）
+var errReadBodyTimeout = errors.New("读取响应体超时")
+
 var App HttpClient
 
 func (*HttpClient) New(url string) *HttpClient       { return NewHttpClient(url) }
@@ -88,7 +104,7 @@ func NewDelete(url string) *HttpClient {
 	return NewHttpClient(url).SetMethod(http.MethodDelete)
 }
 
-// SetCert 设置SSL证书
+// SetCert 设置SSL证书：使用会话时需要通过会话的SetRootCA设置，否则生成请求时返回GenerateRequestErr
 func (my *HttpClient) SetCert(filename string) *HttpClient {
 	var e error
 
@@ -100,6 +116,26 @@ func (my *HttpClient) SetCert(filename string) *HttpClient {
 	return my
 }
 
+// SetSession 设置http会话：使用会话的Transport复用链接，此时建立链接、TLS握手、等待响应头超时以及证书由会话配置
+func (my *HttpClient) SetSession(session *HttpSession) *HttpClient {
+	my.session = session
+	if session != nil && session.Err != nil {
+		my.Err = session.Err
+	}
+
+	return my
+}
+
+// SetTransport 设置自定义Transport：优先级高于会话和证书配置，可用于模拟、录制回放
+func (my *HttpClient) SetTransport(roundTripper http.RoundTripper) *HttpClient {
+	my.roundTripper = roundTripper
+
+	return my
+}
+
+// GetSession 获取http会话
+func (my *HttpClient) GetSession() *HttpSession { return my.session }
+
 // SetUrl 设置请求地址
 func (my *HttpClient) SetUrl(url string) *HttpClient {
 	my.requestUrl = url
@@ -388,21 +424,76 @@ func (my *HttpClient) AppendHeaderAccept(keys ...Accept) *HttpClient {
 
 // SetTimeoutSecond 设置超时
 func (my *HttpClient) SetTimeoutSecond(timeoutSecond int64) *HttpClient {
-	my.timeoutSecond = timeoutSecond
+	return my.SetTimeout(time.Duration(timeoutSecond) * time.Second)
+}
+
+// SetTimeout 设置单次请求总超时（包含建立链接、发送请求、读取响应体）
+func (my *HttpClient) SetTimeout(timeout time.Duration) *HttpClient {
+	my.timeout = timeout
+
+	return my
+}
+
+// SetDialTimeout 设置建立链接超时：使用会话时需要在会话中设置，否则生成请求时返回GenerateRequestErr
+func (my *HttpClient) SetDialTimeout(timeout time.Duration) *HttpClient {
+	my.dialTimeout = timeout
 
 	return my
 }
 
+// SetTLSHandshakeTimeout 设置TLS握手超时：使用会话时需要在会话中设置，否则生成请求时返回GenerateRequestErr
+func (my *HttpClient) SetTLSHandshakeTimeout(timeout time.Duration) *HttpClient {
+	my.tlsTimeout = timeout
+
+	return my
+}
+
+// SetResponseHeaderTimeout 设置等待响应头超时：使用会话时需要在会话中设置，否则生成请求时返回GenerateRequestErr
+func (my *HttpClient) SetResponseHeaderTimeout(timeout time.Duration) *HttpClient {
+	my.headerTimeout = timeout
+
+	return my
+}
+
+// SetReadBodyTimeout 设置读取响应体超时：从收到响应头开始计时
+func (my *HttpClient) SetReadBodyTimeout(timeout time.Duration) *HttpClient {
+	my.readBodyTimeout = timeout
+
+	return my
+}
+
+// SetContext 设置上下文：上下文取消时中断请求
+func (my *HttpClient) SetContext(ctx context.Context) *HttpClient {
+	my.ctx = ctx
+
+	return my
+}
+
+// GetContext 获取上下文
+func (my *HttpClient) GetContext() context.Context {
+	if my.ctx == nil {
+		return context.Background()
+	}
+
+	return my.ctx
+}
+
 // GetResponse 获取响应对象
 func (my *HttpClient) GetResponse() *http.Response { return my.response }
 
-// ParseByContentType 根据响应头Content-Type自动解析响应体
+// ParseByContentType 根据响应头Content-Type自动解析响应体：支持json、xml、yaml、表单；Content-Type为空或不支持时不做处理
 func (my *HttpClient) ParseByContentType(target any) *HttpClient {
-	switch ContentType(my.GetResponse().Header.Get("Content-Type")) {
-	case ContentTypeJson:
-		my.GetResponseJsonBody(target)
-	case ContentTypeXml:
-		my.GetResponseXmlBody(target)
+	if my.response == nil || len(my.responseBody) == 0 {
+		return my
+	}
+
+	contentType := my.response.Header.Get("Content-Type")
+	if format := mediaFormat(contentType); format == "" || format == "unknown" {
+		return my
+	}
+
+	if e := decodeByContentType(contentType, my.responseBody, target); e != nil {
+		my.Err = e
 	}
 
 	return my
@@ -468,7 +559,7 @@ func (my *HttpClient) GetRequest() *http.Request { return my.request }
 func (my *HttpClient) GenerateRequest() *HttpClient {
 	var e error
 
-	my.request, e = http.NewRequest(my.requestMethod, my.requestUrl, bytes.NewReader(my.requestBody))
+	my.request, e = http.NewRequestWithContext(my.GetContext(), my.requestMethod, my.getUrl(), bytes.NewReader(my.requestBody))
 	if e != nil {
 		my.Err = GenerateRequestErr.Wrap(e)
 		return my
@@ -477,27 +568,26 @@ func (my *HttpClient) GenerateRequest() *HttpClient {
 	// 设置请求头
 	my.addHeaders()
 
-	// 设置url参数
-	my.setQueries()
-
 	// 检查请求对象
 	if my.Err = my.check(); my.Err != nil {
 		return my
 	}
 
-	// 创建一个新的证书池，并将证书添加到池中
-	if len(my.cert) > 0 {
-		certPool := x509.NewCertPool()
-		if !certPool.AppendCertsFromPEM(my.cert) {
-			my.Err = GenerateCertErr.Panic()
+	// 使用会话时由会话提供Transport：httpClient上的分阶段超时、证书不会生效
+	if my.session != nil {
+		if my.dialTimeout > 0 || my.tlsTimeout > 0 || my.headerTimeout > 0 || len(my.cert) > 0 {
+			my.Err = GenerateRequestErr.New("使用会话时，建立链接、TLS握手、等待响应头超时以及证书需要在会话中设置")
 			return my
 		}
+		if my.Err = my.session.Err; my.Err == nil {
+			my.isReady = true
+		}
+		return my
+	}
 
-		// 创建一个新的TLS配置
-		tlsConfig := &tls.Config{RootCAs: certPool}
-
-		// 创建一个新的Transport
-		my.transport = &http.Transport{TLSClientConfig: tlsConfig}
+	// 证书与分阶段超时：按配置获取共享的Transport，配置修改后重新获取
+	if my.transport, my.Err = getTransport(my.cert, my.dialTimeout, my.tlsTimeout, my.headerTimeout); my.Err != nil {
+		return my
 	}
 
 	my.isReady = true
@@ -518,28 +608,83 @@ func (my *HttpClient) beforeSend() *http.Client {
 
 	client := &http.Client{}
 
-	// 发送新的请求
-	if my.transport != nil {
+	// 发送新的请求：http.Client本身很轻量，链接复用由Transport负责
+	if my.roundTripper != nil {
+		client.Transport = my.roundTripper
+	} else if my.session != nil {
+		client.Transport = my.session.transport
+	} else if my.transport != nil {
 		client.Transport = my.transport
 	}
 
+	// 设置拦截器
+	if interceptors := my.getInterceptors(); len(interceptors) > 0 {
+		base := client.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		client.Transport = &interceptorTransport{base: base, interceptors: interceptors}
+	}
+
 	// 设置超时
-	if my.timeoutSecond > 0 {
-		client.Timeout = time.Duration(my.timeoutSecond) * time.Second
+	if my.timeout > 0 {
+		client.Timeout = my.timeout
 	}
 
 	return client
 }
 
+// hasOwnTransport 是否配置了自身的Transport：自定义Transport、会话、证书或分阶段超时
+func (my *HttpClient) hasOwnTransport() bool {
+	return my.roundTripper != nil || my.session != nil || len(my.cert) > 0 ||
+		my.dialTimeout > 0 || my.tlsTimeout > 0 || my.headerTimeout > 0
+}
+
+// do 执行请求：返回本次请求的上下文和取消方法，调用方读取完响应体后需要调用取消方法
+func (my *HttpClient) do(client *http.Client) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(my.GetContext())
+	my.request = my.request.WithContext(ctx)
+
+	if my.response, my.Err = client.Do(my.request); my.Err != nil {
+		my.Err = classifyRequestError(ctx, my.Err)
+		return ctx, cancel
+	}
+
+	// 读取响应体超时
+	if my.readBodyTimeout > 0 {
+		timer := time.AfterFunc(my.readBodyTimeout, func() { cancel(errReadBodyTimeout) })
+		return ctx, func(cause error) {
+			timer.Stop()
+			cancel(cause)
+		}
+	}
+
+	return ctx, cancel
+}
+
+// SendWithContext 使用上下文发送请求
+func (my *HttpClient) SendWithContext(ctx context.Context) *HttpClient {
+	return my.SetContext(ctx).Send()
+}
+
 // Download 使用下载器下载文件
 func (my *HttpClient) Download(filename string) *HttpClientDownload {
 	return HttpClientDownloadApp.New(my, filename)
 }
 
-// Send 发送请求
+// Send 发送请求：设置了重试策略时按策略重试
 func (my *HttpClient) Send() *HttpClient {
 	defer func() { my.isReady = false }()
 
+	if my.retryPolicy != nil {
+		return my.sendWithRetry()
+	}
+
+	return my.sendOnce()
+}
+
+// sendOnce 发送一次请求
+func (my *HttpClient) sendOnce() *HttpClient {
 	client := my.beforeSend()
 	if my.Err != nil {
 		return my
@@ -547,7 +692,8 @@ func (my *HttpClient) Send() *HttpClient {
 
 	my.request.Header.Set("Content-Length", fmt.Sprintf("%d", len(my.requestBody)))
 
-	my.response, my.Err = client.Do(my.request)
+	ctx, cancel := my.do(client)
+	defer cancel(nil)
 	if my.Err != nil {
 		return my
 	}
@@ -556,14 +702,14 @@ func (my *HttpClient) Send() *HttpClient {
 	// 读取新的响应的主体
 	if my.response.ContentLength > 1*1024*1024 { // 1MB
 		if _, my.Err = io.Copy(my.responseBodyBuffer, my.response.Body); my.Err != nil {
-			my.Err = ReadResponseErr.Wrap(my.Err)
+			my.Err = classifyReadError(ctx, my.Err)
 			return my
 		}
 		my.responseBody = my.responseBodyBuffer.Bytes()
 	} else {
 		my.responseBody, my.Err = io.ReadAll(my.response.Body)
 		if my.Err != nil {
-			my.Err = ReadResponseErr.Wrap(my.Err)
+			my.Err = classifyReadError(ctx, my.Err)
 			return my
 		}
 	}
@@ -586,16 +732,18 @@ func (my *HttpClient) check() error {
 	return nil
 }
 
-// 设置url参数
-func (my *HttpClient) setQueries() {
-	if len(my.requestQueries) > 0 {
-		queries := url.Values{}
-		for k, v := range my.requestQueries {
-			queries.Add(k, v)
-		}
+// 获取带url参数的请求地址
+func (my *HttpClient) getUrl() string {
+	if len(my.requestQueries) == 0 {
+		return my.requestUrl
+	}
 
-		my.requestUrl = str.BufferApp.NewByString(my.requestUrl).String("?", queries.Encode()).ToString()
+	queries := url.Values{}
+	for k, v := range my.requestQueries {
+		queries.Add(k, v)
 	}
+
+	return str.BufferApp.NewByString(my.requestUrl).String(operation.Ternary(strings.Contains(my.requestUrl, "?"), "&", "?"), queries.Encode()).ToString()
 }
 
 // 设置请求头
@@ -604,3 +752,37 @@ func (my *HttpClient) addHeaders() {
 		my.request.Header[k] = append(my.request.Header[k], v...)
 	}
 }
+
+// classifyRequestError 请求错误分类：取消、超时、拦截器错误、网络错误
+func classifyRequestError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(context.Cause(ctx), errReadBodyTimeout):
+		return RequestTimeoutErr.Wrap(err)
+	case errors.Is(err, context.Canceled):
+		return RequestCanceledErr.Wrap(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return RequestTimeoutErr.Wrap(err)
+	}
+
+	var intercepted *interceptedError
+	if errors.As(err, &intercepted) {
+		return InterceptErr.Wrap(intercepted.err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return RequestTimeoutErr.Wrap(err)
+	}
+
+	return RequestNetworkErr.Wrap(err)
+}
+
+// classifyReadError 读取响应体错误分类：上下文已结束时按请求错误分类，否则为读取响应体失败
+func classifyReadError(ctx context.Context, err error) error {
+	var netErr net.Error
+	if ctx.Err() != nil || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return classifyRequestError(ctx, err)
+	}
+
+	return ReadResponseErr.Wrap(err)
+}
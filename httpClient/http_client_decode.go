@@ -0,0 +1,209 @@
+package httpClient
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// ResponseMeta 响应元信息
+	ResponseMeta struct {
+		StatusCode  int
+		Status      string
+		Header      http.Header
+		ContentType string
+		Duration    time.Duration
+		Attempts    []*SendAttempt
+	}
+
+	// HttpStatusError 非2xx响应错误
+	HttpStatusError struct {
+		StatusCode int
+		Status     string
+		Header     http.Header
+		Body       []byte // 响应体片段，最多bodySnippetSize字节
+		Envelope   any    // 错误信封：使用DoWithEnvelope时为解析后的错误信封指针
+	}
+)
+
+// bodySnippetSize 错误中保留的响应体长度
+const bodySnippetSize = 512
+
+var HttpStatusErr HttpStatusError
+
+func (my *HttpStatusError) Error() string {
+	if len(my.Body) == 0 {
+		return fmt.Sprintf("响应状态错误：%s", my.Status)
+	}
+
+	return fmt.Sprintf("响应状态错误：%s：%s", my.Status, my.Body)
+}
+
+func (my *HttpStatusError) Is(target error) bool { return reflect.DeepEqual(target, &HttpStatusErr) }
+
+// Do 发送请求并按响应头Content-Type解析响应体：非2xx响应返回*HttpStatusError
+func Do[T any](hc *HttpClient) (T, *ResponseMeta, error) {
+	return doDecode[T, struct{}](hc, false)
+}
+
+// DoWithEnvelope 同Do，非2xx响应时将响应体解析为错误信封E，可通过GetEnvelope获取
+func DoWithEnvelope[T, E any](hc *HttpClient) (T, *ResponseMeta, error) {
+	return doDecode[T, E](hc, true)
+}
+
+// GetEnvelope 从错误中获取错误信封
+func GetEnvelope[E any](err error) (*E, bool) {
+	var statusErr *HttpStatusError
+	if !errors.As(err, &statusErr) {
+		return nil, false
+	}
+
+	envelope, ok := statusErr.Envelope.(*E)
+
+	return envelope, ok
+}
+
+// doDecode 发送请求并解析响应体
+func doDecode[T, E any](hc *HttpClient, withEnvelope bool) (T, *ResponseMeta, error) {
+	var target T
+
+	start := time.Now()
+	hc.Send()
+
+	if hc.response == nil {
+		return target, nil, hc.Err
+	}
+
+	meta := &ResponseMeta{
+		StatusCode:  hc.response.StatusCode,
+		Status:      hc.response.Status,
+		Header:      hc.response.Header,
+		ContentType: hc.response.Header.Get("Content-Type"),
+		Duration:    time.Since(start),
+		Attempts:    hc.attempts,
+	}
+
+	if hc.Err != nil {
+		return target, meta, hc.Err
+	}
+
+	if hc.response.StatusCode < http.StatusOK || hc.response.StatusCode >= http.StatusMultipleChoices {
+		statusErr := &HttpStatusError{
+			StatusCode: hc.response.StatusCode,
+			Status:     hc.response.Status,
+			Header:     hc.response.Header,
+			Body:       hc.responseBody[:min(len(hc.responseBody), bodySnippetSize)],
+		}
+
+		if withEnvelope && len(hc.responseBody) > 0 {
+			envelope := new(E)
+			if decodeByContentType(meta.ContentType, hc.responseBody, envelope) == nil {
+				statusErr.Envelope = envelope
+			}
+		}
+
+		return target, meta, statusErr
+	}
+
+	if len(hc.responseBody) == 0 {
+		return target, meta, nil
+	}
+
+	if err := decodeByContentType(meta.ContentType, hc.responseBody, &target); err != nil {
+		return target, meta, err
+	}
+
+	return target, meta, nil
+}
+
+// decodeByContentType 根据Content-Type解析响应体：支持json、xml、yaml、表单；target为*string或*[]byte时直接赋值
+func decodeByContentType(contentType string, body []byte, target any) error {
+	switch t := target.(type) {
+	case *string:
+		*t = string(body)
+		return nil
+	case *[]byte:
+		*t = body
+		return nil
+	}
+
+	var err error
+
+	switch mediaFormat(contentType) {
+	case "json", "":
+		if err = json.Unmarshal(body, target); err != nil {
+			return UnmarshalJsonErr.Wrap(err)
+		}
+	case "xml":
+		if err = xml.Unmarshal(body, target); err != nil {
+			return UnmarshalXmlErr.Wrap(err)
+		}
+	case "yaml":
+		if err = yaml.Unmarshal(body, target); err != nil {
+			return DecodeErr.Wrap(err)
+		}
+	case "form":
+		return decodeForm(body, target)
+	default:
+		return DecodeErr.New(fmt.Sprintf("不支持的Content-Type：%s", contentType))
+	}
+
+	return nil
+}
+
+// mediaFormat 根据Content-Type获取响应体格式：json、xml、yaml、form；Content-Type为空时返回空，不支持时返回unknown
+func mediaFormat(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	switch {
+	case mediaType == "":
+		return ""
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return "json"
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return "xml"
+	case mediaType == "application/yaml" || mediaType == "application/x-yaml" || mediaType == "text/yaml" || strings.HasSuffix(mediaType, "+yaml"):
+		return "yaml"
+	case mediaType == "application/x-www-form-urlencoded":
+		return "form"
+	default:
+		return "unknown"
+	}
+}
+
+// decodeForm 解析表单响应体
+func decodeForm(body []byte, target any) error {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return DecodeErr.Wrap(err)
+	}
+
+	switch t := target.(type) {
+	case *url.Values:
+		*t = values
+	case *map[string][]string:
+		*t = values
+	case *map[string]string:
+		*t = make(map[string]string, len(values))
+		for k := range values {
+			(*t)[k] = values.Get(k)
+		}
+	default:
+		return DecodeErr.New(fmt.Sprintf("表单响应体不支持解析到：%T", target))
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package httpClient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseByContentType(t *testing.T) {
+	type user struct {
+		Name string `json:"name" xml:"name" yaml:"name"`
+	}
+
+	mock := MockTransportApp.New()
+	mock.On(http.MethodGet, "/json").ReplyWithHeaders(http.StatusOK, map[string]string{"Content-Type": "application/json; charset=utf-8"}, `{"name":"json"}`)
+	mock.On(http.MethodGet, "/xml").ReplyWithHeaders(http.StatusOK, map[string]string{"Content-Type": "application/xml"}, `<user><name>xml</name></user>`)
+	mock.On(http.MethodGet, "/yaml").ReplyWithHeaders(http.StatusOK, map[string]string{"Content-Type": "application/yaml"}, "name: yaml")
+	mock.On(http.MethodGet, "/html").ReplyWithHeaders(http.StatusOK, map[string]string{"Content-Type": "text/html"}, "<html></html>")
+	mock.On(http.MethodGet, "/empty").Reply(http.StatusOK, "plain")
+
+	t.Run("按Content-Type解析", func(t *testing.T) {
+		for _, name := range []string{"json", "xml", "yaml"} {
+			var target user
+			if hc := NewGet("http://mock/" + name).SetTransport(mock).Send().ParseByContentType(&target); hc.Err != nil || target.Name != name {
+				t.Fatalf("%s：解析失败：%v %+v", name, hc.Err, target)
+			}
+		}
+	})
+
+	t.Run("不支持的Content-Type不做处理", func(t *testing.T) {
+		for _, name := range []string{"html", "empty"} {
+			target := user{Name: "unchanged"}
+			if hc := NewGet("http://mock/" + name).SetTransport(mock).Send().ParseByContentType(&target); hc.Err != nil || target.Name != "unchanged" {
+				t.Fatalf("%s：不应设置Err或修改目标：%v %+v", name, hc.Err, target)
+			}
+		}
+	})
+
+	t.Run("Do对不支持的Content-Type返回DecodeErr", func(t *testing.T) {
+		if _, _, err := Do[user](NewGet("http://mock/html").SetTransport(mock)); !errors.Is(err, &DecodeErr) {
+			t.Fatalf("期望DecodeErr，实际：%v", err)
+		}
+
+		if target, _, err := Do[user](NewGet("http://mock/json").SetTransport(mock)); err != nil || target.Name != "json" {
+			t.Fatalf("解析失败：%v %+v", err, target)
+		}
+	})
+}
+
+func TestDoStatusError(t *testing.T) {
+	type (
+		user struct {
+			Name string `json:"name"`
+		}
+
+		envelope struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+	)
+
+	long := strings.Repeat("x", bodySnippetSize+100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"ok"}`))
+		case "/envelope":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":40001,"message":"参数错误"}`))
+		case "/long":
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(long))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("2xx解析响应体", func(t *testing.T) {
+		target, meta, err := DoWithEnvelope[user, envelope](NewGet(server.URL + "/ok"))
+		if err != nil || target.Name != "ok" {
+			t.Fatalf("解析失败：%v %+v", err, target)
+		}
+		if meta.StatusCode != http.StatusOK || meta.ContentType != "application/json" {
+			t.Fatalf("响应元信息错误：%+v", meta)
+		}
+	})
+
+	t.Run("非2xx返回HttpStatusError并截断响应体", func(t *testing.T) {
+		_, meta, err := Do[user](NewGet(server.URL + "/long"))
+
+		var statusErr *HttpStatusError
+		if !errors.As(err, &statusErr) || !errors.Is(err, &HttpStatusErr) {
+			t.Fatalf("期望HttpStatusError，实际：%v", err)
+		}
+		if statusErr.StatusCode != http.StatusInternalServerError || statusErr.Status != "500 Internal Server Error" || meta.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("状态码错误：%d %s", statusErr.StatusCode, statusErr.Status)
+		}
+		if string(statusErr.Body) != long[:bodySnippetSize] {
+			t.Fatalf("响应体片段应截断为%d字节：%d", bodySnippetSize, len(statusErr.Body))
+		}
+		if _, ok := GetEnvelope[envelope](err); ok {
+			t.Fatal("Do不应解析错误信封")
+		}
+	})
+
+	t.Run("解析错误信封", func(t *testing.T) {
+		_, _, err := DoWithEnvelope[user, envelope](NewGet(server.URL + "/envelope"))
+		if !errors.Is(err, &HttpStatusErr) {
+			t.Fatalf("期望HttpStatusError，实际：%v", err)
+		}
+
+		// 包装后仍可获取错误信封
+		got, ok := GetEnvelope[envelope](fmt.Errorf("调用失败：%w", err))
+		if !ok || got.Code != 40001 || got.Message != "参数错误" {
+			t.Fatalf("错误信封错误：%+v", got)
+		}
+		if _, ok = GetEnvelope[user](err); ok {
+			t.Fatal("信封类型不匹配时应返回false")
+		}
+	})
+
+	t.Run("无法解析时不设置错误信封", func(t *testing.T) {
+		_, _, err := DoWithEnvelope[user, envelope](NewGet(server.URL + "/long"))
+		if !errors.Is(err, &HttpStatusErr) {
+			t.Fatalf("期望HttpStatusError，实际：%v", err)
+		}
+		if _, ok := GetEnvelope[envelope](err); ok {
+			t.Fatal("响应体不是信封格式时不应设置错误信封")
+		}
+
+		if _, _, err = DoWithEnvelope[user, envelope](NewGet(server.URL + "/empty")); !errors.Is(err, &HttpStatusErr) {
+			t.Fatalf("期望HttpStatusError，实际：%v", err)
+		}
+		if _, ok := GetEnvelope[envelope](err); ok {
+			t.Fatal("空响应体不应设置错误信封")
+		}
+	})
+
+	t.Run("请求失败返回原错误", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		if _, meta, err := Do[user](NewGet(closed.URL)); !errors.Is(err, &RequestNetworkErr) || meta != nil {
+			t.Fatalf("期望RequestNetworkErr，实际：%v", err)
+		}
+	})
+}
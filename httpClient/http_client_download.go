@@ -1,22 +1,67 @@
 package httpClient
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/jericho-yu/aid/array"
 	"github.com/jericho-yu/aid/dict"
+	"github.com/jericho-yu/aid/digest"
 	processBar "github.com/schollz/progressbar/v3"
 )
 
-type HttpClientDownload struct {
-	httpClient     *HttpClient
-	filename       string
-	processContent string
-}
+type (
+	HttpClientDownload struct {
+		httpClient        *HttpClient
+		filename          string
+		processContent    string
+		resume            bool
+		segments          int
+		checksumAlgorithm ChecksumAlgorithm
+		checksum          string
+	}
 
-var HttpClientDownloadApp HttpClientDownload
+	// ChecksumAlgorithm 文件校验算法
+	ChecksumAlgorithm string
+
+	// downloadState 断点续传状态：保存在临时文件旁的sidecar文件中
+	downloadState struct {
+		Url          string             `json:"url"`
+		ETag         string             `json:"etag"`
+		LastModified string             `json:"lastModified"`
+		Size         int64              `json:"size"`
+		Segments     []*downloadSegment `json:"segments"`
+	}
+
+	// downloadSegment 分段下载的一段：[Start, End]闭区间，Done为已下载字节数
+	downloadSegment struct {
+		Start int64 `json:"start"`
+		End   int64 `json:"end"`
+		Done  int64 `json:"done"`
+	}
+)
+
+const (
+	ChecksumMd5    ChecksumAlgorithm = "md5"
+	ChecksumSha256 ChecksumAlgorithm = "sha256"
+	ChecksumSm3    ChecksumAlgorithm = "sm3"
+)
+
+var (
+	HttpClientDownloadApp HttpClientDownload
+
+	// errRemoteChanged 分段下载时远端文件已变化（用于区分失败原因）
+	errRemoteChanged = errors.New("远端文件已变化")
+)
 
 // New 实例化http客户端下载器
 func (*HttpClientDownload) New(httpClient *HttpClient, filename string) *HttpClientDownload {
@@ -30,7 +75,29 @@ func (my *HttpClientDownload) SetProcessContent(processContent string) *HttpClie
 	return my
 }
 
-// SaveLocal 保存到本地
+// SetResume 设置断点续传：下载中断时保留临时文件（filename.part）和状态文件（filename.part.json），再次下载时通过Range请求继续
+func (my *HttpClientDownload) SetResume(resume bool) *HttpClientDownload {
+	my.resume = resume
+
+	return my
+}
+
+// SetSegments 设置并行分段数：大于1时按Range分段并行下载，服务端不支持Range时退化为单流下载
+func (my *HttpClientDownload) SetSegments(segments int) *HttpClientDownload {
+	my.segments = segments
+
+	return my
+}
+
+// SetChecksum 设置期望的文件摘要（十六进制）：下载完成后校验，不一致时不替换目标文件
+func (my *HttpClientDownload) SetChecksum(algorithm ChecksumAlgorithm, checksum string) *HttpClientDownload {
+	my.checksumAlgorithm = algorithm
+	my.checksum = checksum
+
+	return my
+}
+
+// SaveLocal 保存到本地：先写入临时文件，下载并校验成功后原子替换目标文件
 func (my *HttpClientDownload) SaveLocal() *HttpClient {
 	defer func() { my.httpClient.isReady = false }()
 
@@ -39,22 +106,482 @@ func (my *HttpClientDownload) SaveLocal() *HttpClient {
 		return my.httpClient
 	}
 
-	if my.httpClient.response, my.httpClient.Err = client.Do(my.httpClient.request); my.httpClient.Err != nil {
+	var err error
+	if my.segments > 1 {
+		err = my.saveSegments(client)
+	} else {
+		err = my.saveStream(client, true)
+	}
+	if err != nil {
+		if !my.resume {
+			my.clean()
+		}
+		my.httpClient.Err = err
+		return my.httpClient
+	}
+
+	if err = my.verify(); err != nil {
+		my.clean()
+		my.httpClient.Err = err
+		return my.httpClient
+	}
+
+	if err = os.Rename(my.partFilename(), my.filename); err != nil {
+		my.httpClient.Err = DownloadErr.Wrap(err)
 		return my.httpClient
+	}
+	_ = os.Remove(my.stateFilename())
+
+	return my.httpClient
+}
+
+// partFilename 临时文件名
+func (my *HttpClientDownload) partFilename() string { return my.filename + ".part" }
+
+// stateFilename 断点续传状态文件名
+func (my *HttpClientDownload) stateFilename() string { return my.partFilename() + ".json" }
+
+// clean 删除临时文件和状态文件
+func (my *HttpClientDownload) clean() {
+	_ = os.Remove(my.partFilename())
+	_ = os.Remove(my.stateFilename())
+}
+
+// loadState 读取断点续传状态：未开启断点续传、状态不存在或与当前请求不匹配时返回nil
+func (my *HttpClientDownload) loadState() *downloadState {
+	if !my.resume {
+		return nil
+	}
+
+	content, err := os.ReadFile(my.stateFilename())
+	if err != nil {
+		return nil
+	}
+
+	state := &downloadState{}
+	if err = json.Unmarshal(content, state); err != nil || state.Url != my.httpClient.request.URL.String() {
+		return nil
+	}
+
+	return state
+}
+
+// saveState 保存断点续传状态
+func (my *HttpClientDownload) saveState(state *downloadState) error {
+	if !my.resume {
+		return nil
+	}
+
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(my.stateFilename(), content, 0644)
+}
+
+// newBar 创建进度条：未设置进度条标题时返回nil
+func (my *HttpClientDownload) newBar(size, done int64) *processBar.ProgressBar {
+	if my.processContent == "" {
+		return nil
+	}
+
+	bar := processBar.DefaultBytes(size, my.processContent)
+	_ = bar.Set64(done)
+
+	return bar
+}
+
+// newRangeRequest 基于原请求创建Range请求：start为0且end小于0时不设置Range；每个请求重新创建请求体，GET、HEAD请求不带请求体
+func (my *HttpClientDownload) newRangeRequest(ctx context.Context, method string, state *downloadState, start, end int64) *http.Request {
+	request := my.httpClient.request.Clone(ctx)
+	request.Method = method
+	request.Header.Del("Content-Length")
+
+	if method == http.MethodGet || method == http.MethodHead {
+		request.Body, request.GetBody, request.ContentLength = http.NoBody, nil, 0
 	} else {
-		defer func() { _ = my.httpClient.response.Body.Close() }()
+		body := my.httpClient.requestBody
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		request.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+		request.ContentLength = int64(len(body))
+	}
 
-		f, _ := os.OpenFile(my.filename, os.O_RDWR|os.O_CREATE, 0644)
-		defer func() { _ = f.Close() }()
+	if start > 0 || end >= 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-%s", start, rangeEnd(end)))
 
-		if my.processContent != "" {
-			_, _ = io.Copy(io.MultiWriter(f, processBar.DefaultBytes(my.httpClient.response.ContentLength, my.processContent)), my.httpClient.response.Body)
-		} else {
-			_, _ = io.Copy(f, my.httpClient.response.Body)
+		// 远端文件变化时服务端返回完整内容而不是片段
+		if state != nil {
+			if state.ETag != "" {
+				request.Header.Set("If-Range", state.ETag)
+			} else if state.LastModified != "" {
+				request.Header.Set("If-Range", state.LastModified)
+			}
 		}
+	}
 
-		return my.httpClient
+	return request
+}
+
+// rangeEnd Range结束位置：小于0表示到文件末尾
+func rangeEnd(end int64) string {
+	if end < 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d", end)
+}
+
+// watchReadBody 读取响应体超时：超时后以errReadBodyTimeout取消ctx，返回停止计时的方法
+func (my *HttpClientDownload) watchReadBody(cancel context.CancelCauseFunc) func() {
+	if my.httpClient.readBodyTimeout <= 0 {
+		return func() {}
+	}
+
+	timer := time.AfterFunc(my.httpClient.readBodyTimeout, func() { cancel(errReadBodyTimeout) })
+
+	return func() { timer.Stop() }
+}
+
+// saveStream 单流下载：开启断点续传时从临时文件末尾继续
+func (my *HttpClientDownload) saveStream(client *http.Client, retryOnMismatch bool) error {
+	var offset int64
+
+	state := my.loadState()
+	if state != nil && len(state.Segments) == 0 {
+		if stat, err := os.Stat(my.partFilename()); err == nil {
+			offset = stat.Size()
+		}
+	} else {
+		state = nil
+	}
+
+	ctx, cancel := context.WithCancelCause(my.httpClient.GetContext())
+	defer cancel(nil)
+
+	// 不覆盖原请求：重新下载时需要基于不带Range的原请求创建
+	request := my.newRangeRequest(ctx, my.httpClient.request.Method, state, offset, -1)
+
+	response, err := client.Do(request)
+	if err != nil {
+		my.httpClient.Err = classifyRequestError(ctx, err)
+		return my.httpClient.Err
+	}
+	defer func() { _ = response.Body.Close() }()
+	defer my.watchReadBody(cancel)()
+	my.httpClient.response = response
+
+	flag := os.O_WRONLY | os.O_CREATE
+	switch {
+	case response.StatusCode == http.StatusPartialContent && offset > 0:
+		flag |= os.O_APPEND
+	case response.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// 临时文件已完整
+		if state.Size > 0 && state.Size == offset {
+			return nil
+		}
+		if !retryOnMismatch {
+			return DownloadErr.New(response.Status)
+		}
+		my.clean()
+		return my.saveStream(client, false)
+	case response.StatusCode >= http.StatusOK && response.StatusCode < http.StatusMultipleChoices:
+		// 服务端返回完整内容，从头开始
+		offset = 0
+		flag |= os.O_TRUNC
+	default:
+		return DownloadErr.New(response.Status)
+	}
+
+	state = &downloadState{
+		Url:          my.httpClient.request.URL.String(),
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+		Size:         totalSize(offset, response.ContentLength),
+	}
+	if err = my.saveState(state); err != nil {
+		return DownloadErr.Wrap(err)
+	}
+
+	file, err := os.OpenFile(my.partFilename(), flag, 0644)
+	if err != nil {
+		return DownloadErr.Wrap(err)
 	}
+	defer func() { _ = file.Close() }()
+
+	var writer io.Writer = file
+	if bar := my.newBar(state.Size, offset); bar != nil {
+		writer = io.MultiWriter(file, bar)
+	}
+
+	if _, err = io.Copy(writer, response.Body); err != nil {
+		return classifyReadError(ctx, err)
+	}
+
+	if err = file.Sync(); err != nil {
+		return DownloadErr.Wrap(err)
+	}
+
+	return nil
+}
+
+// totalSize 计算文件总大小：未知时为-1
+func totalSize(offset, contentLength int64) int64 {
+	if contentLength < 0 {
+		return -1
+	}
+
+	return offset + contentLength
+}
+
+// probe 探测远端文件：大小、ETag、Last-Modified以及是否支持Range
+func (my *HttpClientDownload) probe(client *http.Client) (*downloadState, bool, error) {
+	request := my.newRangeRequest(my.httpClient.GetContext(), http.MethodHead, nil, 0, -1)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, false, classifyRequestError(request.Context(), err)
+	}
+	_ = response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, false, nil
+	}
+
+	state := &downloadState{
+		Url:          my.httpClient.request.URL.String(),
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+		Size:         response.ContentLength,
+	}
+
+	return state, response.ContentLength > 0 && strings.EqualFold(response.Header.Get("Accept-Ranges"), "bytes"), nil
+}
+
+// saveSegments 分段并行下载：每段写入临时文件的对应位置
+func (my *HttpClientDownload) saveSegments(client *http.Client) error {
+	state := my.loadState()
+	if state != nil {
+		if _, err := os.Stat(my.partFilename()); err != nil {
+			state = nil
+		}
+	}
+
+	if state == nil || len(state.Segments) == 0 {
+		var (
+			err          error
+			acceptRanges bool
+		)
+
+		if state, acceptRanges, err = my.probe(client); err != nil {
+			return err
+		}
+		if !acceptRanges {
+			return my.saveStream(client, true)
+		}
+
+		state.Segments = splitSegments(state.Size, my.segments)
+
+		file, err := os.OpenFile(my.partFilename(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return DownloadErr.Wrap(err)
+		}
+		err = file.Truncate(state.Size)
+		_ = file.Close()
+		if err != nil {
+			return DownloadErr.Wrap(err)
+		}
+
+		if err = my.saveState(state); err != nil {
+			return DownloadErr.Wrap(err)
+		}
+	}
+
+	file, err := os.OpenFile(my.partFilename(), os.O_WRONLY, 0644)
+	if err != nil {
+		return DownloadErr.Wrap(err)
+	}
+
+	var done int64
+	for _, segment := range state.Segments {
+		done += segment.Done
+	}
+	bar := my.newBar(state.Size, done)
+
+	ctx, cancel := context.WithCancelCause(my.httpClient.GetContext())
+	defer cancel(nil)
+
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		firstErr error
+	)
+
+	for _, segment := range state.Segments {
+		if segment.Start+segment.Done > segment.End {
+			continue
+		}
+
+		wg.Add(1)
+		go func(segment *downloadSegment) {
+			defer wg.Done()
+
+			if err := my.saveSegment(ctx, client, file, state, segment, &lock, bar); err != nil {
+				lock.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel(err)
+				}
+				lock.Unlock()
+			}
+		}(segment)
+	}
+
+	// 任意一段失败时已取消其他段，等待所有段退出后再处理临时文件
+	wg.Wait()
+
+	if errors.Is(firstErr, errRemoteChanged) {
+		// 远端文件已变化或服务端不再支持Range，下次从头下载
+		_ = file.Close()
+		my.clean()
+		return DownloadErr.Wrap(firstErr)
+	}
+
+	if err = my.saveState(state); err != nil && firstErr == nil {
+		firstErr = DownloadErr.Wrap(err)
+	}
+	if firstErr == nil {
+		if err = file.Sync(); err != nil {
+			firstErr = DownloadErr.Wrap(err)
+		}
+	}
+	_ = file.Close()
+
+	return firstErr
+}
+
+// saveSegment 下载一段
+func (my *HttpClientDownload) saveSegment(ctx context.Context, client *http.Client, file *os.File, state *downloadState, segment *downloadSegment, lock *sync.Mutex, bar *processBar.ProgressBar) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	lock.Lock()
+	start := segment.Start + segment.Done
+	lock.Unlock()
+
+	request := my.newRangeRequest(ctx, my.httpClient.request.Method, state, start, segment.End)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return classifyRequestError(ctx, err)
+	}
+	defer func() { _ = response.Body.Close() }()
+	defer my.watchReadBody(cancel)()
+
+	if response.StatusCode != http.StatusPartialContent {
+		if response.StatusCode == http.StatusOK {
+			return errRemoteChanged
+		}
+		return DownloadErr.New(response.Status)
+	}
+
+	buffer := make([]byte, 32*1024)
+	for {
+		n, readErr := response.Body.Read(buffer)
+		if n > 0 {
+			lock.Lock()
+			offset := segment.Start + segment.Done
+			lock.Unlock()
+
+			if offset+int64(n)-1 > segment.End {
+				return DownloadErr.New("分段响应长度超出范围")
+			}
+
+			if _, err = file.WriteAt(buffer[:n], offset); err != nil {
+				return DownloadErr.Wrap(err)
+			}
+
+			lock.Lock()
+			segment.Done += int64(n)
+			lock.Unlock()
+
+			if bar != nil {
+				_ = bar.Add(n)
+			}
+		}
+
+		if readErr != nil {
+			if errors.Is(readErr, io.EOF) {
+				break
+			}
+			return classifyReadError(ctx, readErr)
+		}
+	}
+
+	if segment.Start+segment.Done <= segment.End {
+		return DownloadErr.New("分段响应不完整")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	// 每完成一段保存一次状态
+	if err = my.saveState(state); err != nil {
+		return DownloadErr.Wrap(err)
+	}
+
+	return nil
+}
+
+// splitSegments 将文件平均分为若干段
+func splitSegments(size int64, count int) []*downloadSegment {
+	if int64(count) > size {
+		count = int(size)
+	}
+
+	segments := make([]*downloadSegment, 0, count)
+	length := size / int64(count)
+	for i := range count {
+		segment := &downloadSegment{Start: int64(i) * length, End: int64(i+1)*length - 1}
+		if i == count-1 {
+			segment.End = size - 1
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
+
+// verify 校验临时文件摘要
+func (my *HttpClientDownload) verify() error {
+	if my.checksumAlgorithm == "" || my.checksum == "" {
+		return nil
+	}
+
+	var (
+		err    error
+		actual string
+	)
+
+	switch my.checksumAlgorithm {
+	case ChecksumMd5:
+		actual, err = digest.Md5File(my.partFilename())
+	case ChecksumSha256:
+		actual, err = digest.Sha256File(my.partFilename())
+	case ChecksumSm3:
+		actual, err = digest.Sm3File(my.partFilename())
+	default:
+		return ChecksumErr.New(fmt.Sprintf("不支持的校验算法：%s", my.checksumAlgorithm))
+	}
+	if err != nil {
+		return ChecksumErr.Wrap(err)
+	}
+
+	if !strings.EqualFold(actual, my.checksum) {
+		return ChecksumErr.New(fmt.Sprintf("期望：%s，实际：%s", my.checksum, actual))
+	}
+
+	return nil
 }
 
 // SendResponse 发送到客户端
@@ -66,7 +593,10 @@ func (my *HttpClientDownload) SendResponse(w http.ResponseWriter, headers map[st
 		return nil
 	}
 
-	if my.httpClient.response, my.httpClient.Err = client.Do(my.httpClient.request); my.httpClient.Err != nil {
+	_, cancel := my.httpClient.do(client)
+	defer cancel(nil)
+
+	if my.httpClient.Err != nil {
 		return nil
 	} else {
 		defer func() { _ = my.httpClient.response.Body.Close() }()
@@ -0,0 +1,225 @@
+package httpClient
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadRequestBody(t *testing.T) {
+	t.Run("POST导出接口每个请求都携带请求体", func(t *testing.T) {
+		content := strings.Repeat("0123456789", 100)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != http.MethodPost || string(body) != `{"id":1}` || r.ContentLength != int64(len(body)) {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			http.ServeContent(w, r, "export.txt", time.Time{}, strings.NewReader(content))
+		}))
+		defer server.Close()
+
+		filename := filepath.Join(t.TempDir(), "export.txt")
+		hc := NewPost(server.URL).SetBody([]byte(`{"id":1}`)).Download(filename).SaveLocal()
+		if hc.Err != nil {
+			t.Fatalf("下载失败：%v", hc.Err)
+		}
+		if got, _ := os.ReadFile(filename); string(got) != content {
+			t.Fatalf("文件内容错误：%d字节", len(got))
+		}
+	})
+}
+
+func TestDownloadSegments(t *testing.T) {
+	t.Run("分段下载", func(t *testing.T) {
+		content := strings.Repeat("abcdefghij", 1000)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "file.txt", time.Time{}, strings.NewReader(content))
+		}))
+		defer server.Close()
+
+		filename := filepath.Join(t.TempDir(), "file.txt")
+		hc := NewGet(server.URL).Download(filename).SetSegments(4).SetResume(true).SaveLocal()
+		if hc.Err != nil {
+			t.Fatalf("下载失败：%v", hc.Err)
+		}
+		if got, _ := os.ReadFile(filename); string(got) != content {
+			t.Fatalf("文件内容错误：%d字节", len(got))
+		}
+		if _, err := os.Stat(filename + ".part.json"); !os.IsNotExist(err) {
+			t.Fatalf("状态文件未删除：%v", err)
+		}
+	})
+
+	t.Run("分段返回完整内容时取消其他段并清理临时文件", func(t *testing.T) {
+		var (
+			content  = strings.Repeat("abcdefghij", 1000)
+			requests atomic.Int32
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.Header().Set("Accept-Ranges", "bytes")
+				w.Header().Set("Content-Length", "10000")
+				return
+			}
+
+			// 第一段返回完整内容，其他段持续写入直到被取消
+			if requests.Add(1) == 1 {
+				_, _ = io.WriteString(w, content)
+				return
+			}
+			w.WriteHeader(http.StatusPartialContent)
+			for {
+				if _, err := io.WriteString(w, "x"); err != nil {
+					return
+				}
+				w.(http.Flusher).Flush()
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(time.Millisecond):
+				}
+			}
+		}))
+		defer server.Close()
+
+		filename := filepath.Join(t.TempDir(), "file.txt")
+		hc := NewGet(server.URL).Download(filename).SetSegments(4).SetResume(true).SaveLocal()
+		if !errors.Is(hc.Err, &DownloadErr) {
+			t.Fatalf("期望DownloadErr，实际：%v", hc.Err)
+		}
+		for _, name := range []string{filename, filename + ".part", filename + ".part.json"} {
+			if _, err := os.Stat(name); !os.IsNotExist(err) {
+				t.Fatalf("%s未清理：%v", name, err)
+			}
+		}
+	})
+}
+
+func TestDownloadReadBodyTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("Content-Length", "100")
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		// 只返回部分内容后停止写入，直到客户端断开
+		if r.Header.Get("Range") != "" {
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		_, _ = io.WriteString(w, "partial")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	for _, segments := range []int{1, 2} {
+		filename := filepath.Join(t.TempDir(), "file.txt")
+		hc := NewGet(server.URL).SetReadBodyTimeout(50 * time.Millisecond).Download(filename).SetSegments(segments).SaveLocal()
+		if !errors.Is(hc.Err, &RequestTimeoutErr) {
+			t.Fatalf("分段数%d：期望RequestTimeoutErr，实际：%v", segments, hc.Err)
+		}
+	}
+}
+
+// writeResumeState 写入断点续传的临时文件和状态文件
+func writeResumeState(t *testing.T, filename, url, part string, size int64) {
+	t.Helper()
+
+	if err := os.WriteFile(filename+".part", []byte(part), 0644); err != nil {
+		t.Fatalf("写入临时文件失败：%v", err)
+	}
+	content, _ := json.Marshal(&downloadState{Url: url, ETag: `"v1"`, Size: size})
+	if err := os.WriteFile(filename+".part.json", content, 0644); err != nil {
+		t.Fatalf("写入状态文件失败：%v", err)
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	content := strings.Repeat("0123456789", 50)
+
+	tests := []struct {
+		name    string
+		part    string
+		size    int64
+		handler func(w http.ResponseWriter, r *http.Request)
+		ranges  []string
+	}{
+		{
+			name: "单流断点续传",
+			part: content[:200],
+			size: int64(len(content)),
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"v1"`)
+				http.ServeContent(w, r, "file.txt", time.Time{}, strings.NewReader(content))
+			},
+			ranges: []string{"bytes=200-"},
+		},
+		{
+			name: "416时删除临时文件并重新下载",
+			part: strings.Repeat("x", 600),
+			size: 700,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"v1"`)
+				http.ServeContent(w, r, "file.txt", time.Time{}, strings.NewReader(content))
+			},
+			ranges: []string{"bytes=600-", ""},
+		},
+		{
+			name: "返回200而不是206时从头下载",
+			part: strings.Repeat("x", 200),
+			size: int64(len(content)),
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, content)
+			},
+			ranges: []string{"bytes=200-"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				lock   sync.Mutex
+				ranges []string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				ranges = append(ranges, r.Header.Get("Range"))
+				lock.Unlock()
+				test.handler(w, r)
+			}))
+			defer server.Close()
+
+			filename := filepath.Join(t.TempDir(), "file.txt")
+			writeResumeState(t, filename, server.URL, test.part, test.size)
+
+			hc := NewGet(server.URL).Download(filename).SetResume(true).SaveLocal()
+			if hc.Err != nil {
+				t.Fatalf("下载失败：%v", hc.Err)
+			}
+			if got, _ := os.ReadFile(filename); string(got) != content {
+				t.Fatalf("文件内容错误：%d字节", len(got))
+			}
+			if !slices.Equal(ranges, test.ranges) {
+				t.Fatalf("Range错误：%q", ranges)
+			}
+			for _, name := range []string{filename + ".part", filename + ".part.json"} {
+				if _, err := os.Stat(name); !os.IsNotExist(err) {
+					t.Fatalf("%s未清理：%v", name, err)
+				}
+			}
+		})
+	}
+}
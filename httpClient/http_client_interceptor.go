@@ -0,0 +1,144 @@
+package httpClient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/jericho-yu/aid/retry"
+)
+
+type (
+	// RoundTrip 执行请求：调用后续拦截器，最终由Transport发送
+	RoundTrip func(request *http.Request) (*http.Response, error)
+
+	// Interceptor 拦截器：可以在调用next之前修改请求，在调用next之后处理响应；不调用next直接返回响应则短路本次请求（如：缓存、模拟）
+	Interceptor func(request *http.Request, next RoundTrip) (*http.Response, error)
+
+	// interceptorTransport 带拦截器链的Transport
+	interceptorTransport struct {
+		base         http.RoundTripper
+		interceptors []Interceptor
+	}
+
+	// interceptedError 拦截器返回的错误（区别于网络错误）
+	interceptedError struct{ err error }
+)
+
+var (
+	globalInterceptors     []Interceptor
+	globalInterceptorsLock sync.RWMutex
+)
+
+// UseGlobalInterceptors 注册全局拦截器：对所有httpClient生效，先于httpClient自身的拦截器执行
+func UseGlobalInterceptors(interceptors ...Interceptor) {
+	globalInterceptorsLock.Lock()
+	defer globalInterceptorsLock.Unlock()
+
+	globalInterceptors = append(globalInterceptors, interceptors...)
+}
+
+// CleanGlobalInterceptors 清空全局拦截器
+func CleanGlobalInterceptors() {
+	globalInterceptorsLock.Lock()
+	defer globalInterceptorsLock.Unlock()
+
+	globalInterceptors = nil
+}
+
+// Use 注册拦截器：按注册顺序由外向内执行
+func (my *HttpClient) Use(interceptors ...Interceptor) *HttpClient {
+	my.interceptors = append(my.interceptors, interceptors...)
+
+	return my
+}
+
+// getInterceptors 获取全局拦截器和httpClient拦截器
+func (my *HttpClient) getInterceptors() []Interceptor {
+	globalInterceptorsLock.RLock()
+	defer globalInterceptorsLock.RUnlock()
+
+	if len(globalInterceptors) == 0 {
+		return my.interceptors
+	}
+
+	interceptors := make([]Interceptor, 0, len(globalInterceptors)+len(my.interceptors))
+	interceptors = append(interceptors, globalInterceptors...)
+
+	return append(interceptors, my.interceptors...)
+}
+
+// RoundTrip 实现http.RoundTripper：拦截器修改的是请求的副本，不影响调用方的请求
+func (my *interceptorTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var baseErr error
+
+	request = request.Clone(request.Context())
+
+	next := RoundTrip(func(request *http.Request) (*http.Response, error) {
+		response, err := my.base.RoundTrip(request)
+		baseErr = err
+		return response, err
+	})
+
+	for i := len(my.interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := my.interceptors[i], next
+		next = func(request *http.Request) (*http.Response, error) { return interceptor(request, inner) }
+	}
+
+	response, err := next(request)
+	if err != nil {
+		if err != baseErr {
+			return nil, &interceptedError{err: err}
+		}
+		return nil, err
+	}
+	if response == nil {
+		return nil, &interceptedError{err: errors.New("拦截器没有返回响应")}
+	}
+
+	// 补全短路返回的响应
+	if response.Body == nil {
+		response.Body = http.NoBody
+	}
+	if response.Request == nil {
+		response.Request = request
+	}
+
+	return response, nil
+}
+
+func (my *interceptedError) Error() string { return my.err.Error() }
+
+func (my *interceptedError) Unwrap() error { return my.err }
+
+// TransportInterceptor 将Transport转换为拦截器：请求直接交给该Transport处理，配合UseGlobalInterceptors可对所有httpClient生效（如：模拟、录制回放）
+func TransportInterceptor(roundTripper http.RoundTripper) Interceptor {
+	return func(request *http.Request, _ RoundTrip) (*http.Response, error) {
+		return roundTripper.RoundTrip(request)
+	}
+}
+
+// BreakerInterceptor 熔断器拦截器：网络错误、超时和5xx响应计为失败，调用方主动取消的请求不计为失败；熔断器打开时不发送请求，HttpClient.Err为InterceptErr
+func BreakerInterceptor(breaker *retry.CircuitBreaker) Interceptor {
+	return func(request *http.Request, next RoundTrip) (*http.Response, error) {
+		done, err := breaker.Allow()
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := next(request)
+		switch {
+		case err == nil && response != nil && response.StatusCode >= http.StatusInternalServerError:
+			done(fmt.Errorf("响应状态错误：%s", response.Status))
+		case errors.Is(err, context.Canceled) || errors.Is(request.Context().Err(), context.Canceled):
+			// 主动取消的请求不能说明服务状态：不计入统计，只归还探测名额
+			done(retry.BreakerIgnored)
+		default:
+			done(err)
+		}
+
+		return response, err
+	}
+}
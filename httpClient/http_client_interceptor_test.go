@@ -0,0 +1,107 @@
+package httpClient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jericho-yu/aid/retry"
+)
+
+func TestInterceptor(t *testing.T) {
+	t.Run("拦截器短路时没有返回响应", func(t *testing.T) {
+		hc := NewGet("http://mock/").Use(func(*http.Request, RoundTrip) (*http.Response, error) { return nil, nil }).Send()
+		if !errors.Is(hc.Err, &InterceptErr) {
+			t.Fatalf("期望InterceptErr，实际：%v", hc.Err)
+		}
+	})
+
+	t.Run("拦截器不修改调用方的请求", func(t *testing.T) {
+		mock := MockTransportApp.New()
+		mock.On(http.MethodGet, "/").WithHeader("X-Trace", "1")
+
+		hc := NewGet("http://mock/").SetTransport(mock).Use(func(request *http.Request, next RoundTrip) (*http.Response, error) {
+			request.Header.Set("X-Trace", "1")
+			return next(request)
+		}).Send()
+		if hc.Err != nil {
+			t.Fatalf("请求失败：%v", hc.Err)
+		}
+		if hc.GetRequest().Header.Get("X-Trace") != "" {
+			t.Fatal("调用方的请求被修改")
+		}
+	})
+}
+
+func TestBreakerInterceptor(t *testing.T) {
+	mock := MockTransportApp.New()
+	mock.On(http.MethodGet, "/ok")
+	mock.On(http.MethodGet, "/error").Reply(http.StatusBadGateway, nil)
+	mock.On(http.MethodGet, "/wait").ReplyFunc(func(request *http.Request) (*http.Response, error) {
+		<-request.Context().Done()
+		return nil, request.Context().Err()
+	})
+
+	t.Run("调用方取消的请求不计为失败", func(t *testing.T) {
+		breaker := retry.CircuitBreakerApp.New("cancel").SetConsecutiveFailures(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		for range 3 {
+			if hc := NewGet("http://mock/wait").SetTransport(mock).Use(BreakerInterceptor(breaker)).SetContext(ctx).Send(); !errors.Is(hc.Err, &RequestCanceledErr) {
+				t.Fatalf("期望RequestCanceledErr，实际：%v", hc.Err)
+			}
+		}
+		if breaker.State() != retry.BreakerStateClosed {
+			t.Fatalf("熔断器不应打开：%s", breaker.State())
+		}
+	})
+
+	t.Run("调用方取消的请求不影响失败统计和半开探测", func(t *testing.T) {
+		breaker := retry.CircuitBreakerApp.New("mixed").SetConsecutiveFailures(2).SetOpenTimeout(10 * time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_ = NewGet("http://mock/error").SetTransport(mock).Use(BreakerInterceptor(breaker)).Send()
+		_ = NewGet("http://mock/wait").SetTransport(mock).Use(BreakerInterceptor(breaker)).SetContext(ctx).Send()
+		_ = NewGet("http://mock/error").SetTransport(mock).Use(BreakerInterceptor(breaker)).Send()
+		if breaker.State() != retry.BreakerStateOpen {
+			t.Fatalf("取消不应重置连续失败：%s", breaker.State())
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for breaker.State() != retry.BreakerStateHalfOpen {
+			if time.Now().After(deadline) {
+				t.Fatal("冷却结束后应进入半开状态")
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		// 取消的探测请求不能关闭熔断器，并归还探测名额
+		_ = NewGet("http://mock/wait").SetTransport(mock).Use(BreakerInterceptor(breaker)).SetContext(ctx).Send()
+		if breaker.State() != retry.BreakerStateHalfOpen {
+			t.Fatalf("取消的探测请求不应改变状态：%s", breaker.State())
+		}
+		if hc := NewGet("http://mock/ok").SetTransport(mock).Use(BreakerInterceptor(breaker)).Send(); hc.Err != nil {
+			t.Fatalf("探测名额应已归还：%v", hc.Err)
+		}
+		if breaker.State() != retry.BreakerStateClosed {
+			t.Fatalf("探测成功后应关闭：%s", breaker.State())
+		}
+	})
+
+	t.Run("5xx响应计为失败", func(t *testing.T) {
+		breaker := retry.CircuitBreakerApp.New("5xx").SetConsecutiveFailures(1)
+
+		_ = NewGet("http://mock/error").SetTransport(mock).Use(BreakerInterceptor(breaker)).Send()
+		if breaker.State() != retry.BreakerStateOpen {
+			t.Fatalf("熔断器应打开：%s", breaker.State())
+		}
+		if hc := NewGet("http://mock/ok").SetTransport(mock).Use(BreakerInterceptor(breaker)).Send(); !errors.Is(hc.Err, &InterceptErr) {
+			t.Fatalf("期望InterceptErr，实际：%v", hc.Err)
+		}
+	})
+}
@@ -0,0 +1,308 @@
+package httpClient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type (
+	// MockTransport 模拟Transport：按请求方法、路径、参数、请求头、json请求体匹配路由并返回预设响应；同时实现http.Handler，可作为进程内模拟服务
+	MockTransport struct {
+		lock   sync.Mutex
+		routes []*MockRoute
+	}
+
+	// MockRoute 模拟路由
+	MockRoute struct {
+		transport *MockTransport
+		method    string
+		path      string
+		queries   map[string]string
+		headers   map[string]string
+		jsonBody  any
+		hasJson   bool
+		times     int // 期望调用次数：小于0表示不校验
+		calls     int
+		reply     func(request *http.Request) (*http.Response, error)
+	}
+)
+
+var MockTransportApp MockTransport
+
+// New 实例化：模拟Transport
+func (*MockTransport) New() *MockTransport { return &MockTransport{} }
+
+// On 添加模拟路由：method为空表示匹配任意方法；默认期望至少调用一次
+func (my *MockTransport) On(method, path string) *MockRoute {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	route := &MockRoute{
+		transport: my,
+		method:    strings.ToUpper(method),
+		path:      path,
+		queries:   map[string]string{},
+		headers:   map[string]string{},
+		times:     -1,
+		reply: func(request *http.Request) (*http.Response, error) {
+			return newMockResponse(request, http.StatusOK, nil, nil), nil
+		},
+	}
+	my.routes = append(my.routes, route)
+
+	return route
+}
+
+// WithQuery 匹配url参数
+func (my *MockRoute) WithQuery(key, value string) *MockRoute {
+	my.queries[key] = value
+
+	return my
+}
+
+// WithHeader 匹配请求头
+func (my *MockRoute) WithHeader(key, value string) *MockRoute {
+	my.headers[key] = value
+
+	return my
+}
+
+// WithJsonBody 匹配json请求体：按json语义比较，与字段顺序、空白无关
+func (my *MockRoute) WithJsonBody(body any) *MockRoute {
+	content, err := json.Marshal(body)
+	if err != nil {
+		panic(MockErr.Wrap(err))
+	}
+
+	my.hasJson = true
+	_ = json.Unmarshal(content, &my.jsonBody)
+
+	return my
+}
+
+// Times 设置期望调用次数
+func (my *MockRoute) Times(times int) *MockRoute {
+	my.times = times
+
+	return my
+}
+
+// Reply 设置响应：body为[]byte或string时原样返回，其他类型按json序列化
+func (my *MockRoute) Reply(statusCode int, body any) *MockRoute {
+	var (
+		content []byte
+		header  = http.Header{}
+	)
+
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		content = b
+	case string:
+		content = []byte(b)
+	default:
+		var err error
+		if content, err = json.Marshal(b); err != nil {
+			panic(MockErr.Wrap(err))
+		}
+		header.Set("Content-Type", "application/json")
+	}
+
+	my.reply = func(request *http.Request) (*http.Response, error) {
+		return newMockResponse(request, statusCode, header.Clone(), content), nil
+	}
+
+	return my
+}
+
+// ReplyWithHeaders 设置带响应头的响应
+func (my *MockRoute) ReplyWithHeaders(statusCode int, headers map[string]string, body any) *MockRoute {
+	my.Reply(statusCode, body)
+
+	reply := my.reply
+	my.reply = func(request *http.Request) (*http.Response, error) {
+		response, err := reply(request)
+		for k, v := range headers {
+			response.Header.Set(k, v)
+		}
+		return response, err
+	}
+
+	return my
+}
+
+// ReplyError 设置返回错误：模拟网络故障
+func (my *MockRoute) ReplyError(err error) *MockRoute {
+	my.reply = func(*http.Request) (*http.Response, error) { return nil, err }
+
+	return my
+}
+
+// ReplyFunc 设置自定义响应方法
+func (my *MockRoute) ReplyFunc(fn func(request *http.Request) (*http.Response, error)) *MockRoute {
+	my.reply = fn
+
+	return my
+}
+
+// Calls 获取调用次数
+func (my *MockRoute) Calls() int {
+	my.transport.lock.Lock()
+	defer my.transport.lock.Unlock()
+
+	return my.calls
+}
+
+// match 判断请求是否匹配路由
+func (my *MockRoute) match(request *http.Request, body []byte) bool {
+	if my.method != "" && my.method != request.Method {
+		return false
+	}
+
+	if my.path != "" && my.path != request.URL.Path {
+		return false
+	}
+
+	queries := request.URL.Query()
+	for k, v := range my.queries {
+		if queries.Get(k) != v {
+			return false
+		}
+	}
+
+	for k, v := range my.headers {
+		if request.Header.Get(k) != v {
+			return false
+		}
+	}
+
+	if my.hasJson {
+		var actual any
+		if json.Unmarshal(body, &actual) != nil || !reflect.DeepEqual(actual, my.jsonBody) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RoundTrip 实现http.RoundTripper：按添加顺序匹配，优先使用未达到期望次数的路由
+func (my *MockTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var body []byte
+	if request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(request.Body); err != nil {
+			return nil, MockErr.Wrap(err)
+		}
+		_ = request.Body.Close()
+		request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	my.lock.Lock()
+	var matched *MockRoute
+	for _, route := range my.routes {
+		if !route.match(request, body) {
+			continue
+		}
+		if matched == nil {
+			matched = route
+		}
+		if route.times < 0 || route.calls < route.times {
+			matched = route
+			break
+		}
+	}
+	if matched != nil {
+		matched.calls++
+	}
+	my.lock.Unlock()
+
+	if matched == nil {
+		return nil, MockErr.New(fmt.Sprintf("没有匹配的模拟路由：%s %s", request.Method, request.URL.String()))
+	}
+
+	return matched.reply(request)
+}
+
+// ServeHTTP 实现http.Handler：作为进程内模拟服务
+func (my *MockTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	response, err := my.RoundTrip(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	for k, v := range response.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(response.StatusCode)
+	_, _ = io.Copy(w, response.Body)
+}
+
+// StartServer 启动进程内模拟服务：使用完毕后需要调用Close
+func (my *MockTransport) StartServer() *httptest.Server { return httptest.NewServer(my) }
+
+// Verify 校验调用次数：设置了Times的路由需要调用对应次数，其他路由至少调用一次
+func (my *MockTransport) Verify() error {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	var failures []string
+	for _, route := range my.routes {
+		if (route.times >= 0 && route.calls != route.times) || (route.times < 0 && route.calls == 0) {
+			failures = append(failures, fmt.Sprintf("%s %s 期望%s次，实际%d次", route.method, route.path, expectTimes(route.times), route.calls))
+		}
+	}
+
+	if len(failures) > 0 {
+		return MockErr.New(strings.Join(failures, "；"))
+	}
+
+	return nil
+}
+
+// Reset 清空调用次数
+func (my *MockTransport) Reset() {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	for _, route := range my.routes {
+		route.calls = 0
+	}
+}
+
+// expectTimes 期望次数描述
+func expectTimes(times int) string {
+	if times < 0 {
+		return "至少1"
+	}
+
+	return fmt.Sprintf("%d", times)
+}
+
+// newMockResponse 创建模拟响应
+func newMockResponse(request *http.Request, statusCode int, header http.Header, body []byte) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}
+}
@@ -0,0 +1,156 @@
+package httpClient
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMockTransportRoute(t *testing.T) {
+	t.Run("按方法、参数、请求头、json请求体匹配路由", func(t *testing.T) {
+		mock := MockTransportApp.New()
+		byQuery := mock.On(http.MethodGet, "/users").WithQuery("id", "1").Reply(http.StatusOK, "one")
+		byHeader := mock.On(http.MethodGet, "/users").WithHeader("X-Tenant", "a").Reply(http.StatusOK, "tenant")
+		byJson := mock.On(http.MethodPost, "/users").WithJsonBody(map[string]any{"name": "x", "age": 1}).Reply(http.StatusCreated, "created")
+
+		cases := []struct {
+			name   string
+			client *HttpClient
+			status int
+			body   string
+		}{
+			{"参数", NewGet("http://mock/users").SetQueries(map[string]string{"id": "1"}), http.StatusOK, "one"},
+			{"请求头", NewGet("http://mock/users").AddHeaders(map[string][]string{"X-Tenant": {"a"}}), http.StatusOK, "tenant"},
+			{"json请求体与字段顺序无关", NewPost("http://mock/users").SetBody([]byte(`{"age":1, "name":"x"}`)), http.StatusCreated, "created"},
+		}
+
+		for _, c := range cases {
+			hc := c.client.SetTransport(mock).Send()
+			if hc.Err != nil {
+				t.Fatalf("%s：请求失败：%v", c.name, hc.Err)
+			}
+			if hc.GetResponse().StatusCode != c.status || string(hc.GetResponseRawBody()) != c.body {
+				t.Fatalf("%s：响应错误：%d %s", c.name, hc.GetResponse().StatusCode, hc.GetResponseRawBody())
+			}
+		}
+
+		if byQuery.Calls() != 1 || byHeader.Calls() != 1 || byJson.Calls() != 1 {
+			t.Fatalf("调用次数错误：%d %d %d", byQuery.Calls(), byHeader.Calls(), byJson.Calls())
+		}
+		if err := mock.Verify(); err != nil {
+			t.Fatalf("校验失败：%v", err)
+		}
+	})
+
+	t.Run("没有匹配的路由时返回MockErr", func(t *testing.T) {
+		mock := MockTransportApp.New()
+		mock.On(http.MethodGet, "/users").WithQuery("id", "1")
+
+		request, _ := http.NewRequest(http.MethodGet, "http://mock/users?id=2", nil)
+		if _, err := mock.RoundTrip(request); !errors.Is(err, &MockErr) {
+			t.Fatalf("期望MockErr，实际：%v", err)
+		}
+		if hc := NewGet("http://mock/users").SetQueries(map[string]string{"id": "2"}).SetTransport(mock).Send(); !errors.Is(hc.Err, &RequestNetworkErr) {
+			t.Fatalf("期望RequestNetworkErr，实际：%v", hc.Err)
+		}
+		if err := mock.Verify(); !errors.Is(err, &MockErr) {
+			t.Fatalf("未调用的路由应校验失败：%v", err)
+		}
+	})
+
+	t.Run("达到期望次数后使用下一个路由", func(t *testing.T) {
+		mock := MockTransportApp.New()
+		mock.On(http.MethodGet, "/retry").Times(1).Reply(http.StatusServiceUnavailable, nil)
+		mock.On(http.MethodGet, "/retry").Times(1).Reply(http.StatusOK, "ok")
+
+		for _, want := range []int{http.StatusServiceUnavailable, http.StatusOK} {
+			hc := NewGet("http://mock/retry").SetTransport(mock).Send()
+			if hc.Err != nil || hc.GetResponse().StatusCode != want {
+				t.Fatalf("期望%d，实际：%v", want, hc.Err)
+			}
+		}
+		if err := mock.Verify(); err != nil {
+			t.Fatalf("校验失败：%v", err)
+		}
+	})
+
+	t.Run("并发请求时读取调用次数", func(t *testing.T) {
+		mock := MockTransportApp.New()
+		route := mock.On(http.MethodGet, "/concurrent")
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_ = NewGet("http://mock/concurrent").SetTransport(mock).Send()
+			}()
+			go func() {
+				defer wg.Done()
+				_ = route.Calls()
+			}()
+		}
+		wg.Wait()
+
+		if route.Calls() != 10 {
+			t.Fatalf("调用次数错误：%d", route.Calls())
+		}
+	})
+}
+
+func TestRecordTransportReplay(t *testing.T) {
+	t.Run("录制后回放", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "golden", "users.json")
+
+		mock := MockTransportApp.New()
+		mock.On(http.MethodPost, "/users").Reply(http.StatusCreated, map[string]any{"id": 1})
+		mock.On(http.MethodGet, "/binary").Reply(http.StatusOK, []byte{0xff, 0x00, 0xfe})
+
+		recorder := RecordTransportApp.New(filename, RecordModeAuto).SetBase(mock)
+		if recorder.GetMode() != RecordModeRecord {
+			t.Fatalf("golden文件不存在时应为录制模式：%s", recorder.GetMode())
+		}
+		if hc := NewPost("http://mock/users").SetBody([]byte(`{"name":"x"}`)).SetHeaders(map[string][]string{"Authorization": {"secret"}}).SetTransport(recorder).Send(); hc.Err != nil {
+			t.Fatalf("录制失败：%v", hc.Err)
+		}
+		if hc := NewGet("http://mock/binary").SetTransport(recorder).Send(); hc.Err != nil {
+			t.Fatalf("录制失败：%v", hc.Err)
+		}
+		if err := recorder.Save(); err != nil {
+			t.Fatalf("保存失败：%v", err)
+		}
+
+		replayer := RecordTransportApp.New(filename, RecordModeAuto)
+		if replayer.GetMode() != RecordModeReplay {
+			t.Fatalf("golden文件存在时应为回放模式：%s", replayer.GetMode())
+		}
+		if err := replayer.Load(); err != nil {
+			t.Fatalf("读取失败：%v", err)
+		}
+		if header := replayer.cassette.Interactions[0].Request.Header.Get("Authorization"); header != "REDACTED" {
+			t.Fatalf("请求头未脱敏：%s", header)
+		}
+
+		hc := NewPost("http://mock/users").SetBody([]byte(`{"name":"x"}`)).SetTransport(replayer).Send()
+		if hc.Err != nil || hc.GetResponse().StatusCode != http.StatusCreated || string(hc.GetResponseRawBody()) != `{"id":1}` {
+			t.Fatalf("回放失败：%v", hc.Err)
+		}
+		hc = NewGet("http://mock/binary").SetTransport(replayer).Send()
+		if hc.Err != nil || string(hc.GetResponseRawBody()) != string([]byte{0xff, 0x00, 0xfe}) {
+			t.Fatalf("二进制回放失败：%v", hc.Err)
+		}
+
+		// 每条记录只回放一次；请求体不同也不匹配
+		request, _ := http.NewRequest(http.MethodGet, "http://mock/binary", nil)
+		if _, err := replayer.RoundTrip(request); !errors.Is(err, &RecordErr) {
+			t.Fatalf("期望RecordErr，实际：%v", err)
+		}
+		request, _ = http.NewRequest(http.MethodPost, "http://mock/users", strings.NewReader(`{"name":"y"}`))
+		if _, err := replayer.RoundTrip(request); !errors.Is(err, &RecordErr) {
+			t.Fatalf("期望RecordErr，实际：%v", err)
+		}
+	})
+}
@@ -1,12 +1,35 @@
 package httpClient
 
 import (
+	"context"
 	"sync"
+	"time"
 )
 
-type Multiple struct {
-	clients []*HttpClient
-}
+type (
+	// Multiple 批量请求：每次New得到一个独立的批次
+	Multiple struct {
+		clients     []*HttpClient
+		session     *HttpSession
+		ctx         context.Context
+		concurrency int
+		failFast    bool
+		onProgress  MultipleProgress
+		results     []*MultipleResult
+	}
+
+	// MultipleResult 批量请求中单个请求的结果
+	MultipleResult struct {
+		Index    int           // 请求在批次中的下标
+		Client   *HttpClient   // 请求对象
+		Err      error         // 请求错误
+		Skipped  bool          // 是否因快速失败未发送
+		Duration time.Duration // 请求耗时
+	}
+
+	// MultipleProgress 批量请求进度回调：done为已完成数量（包含跳过），total为总数量；回调串行执行
+	MultipleProgress func(done, total int, result *MultipleResult)
+)
 
 var MultipleApp Multiple
 
@@ -15,7 +38,7 @@ func (*Multiple) New() *Multiple { return NewMultiple() }
 // NewMultiple 实例化：批量请求对象
 //
 //go:fix 推荐使用New方法
-func NewMultiple() *Multiple { return &MultipleApp }
+func NewMultiple() *Multiple { return &Multiple{} }
 
 // Append 添加httpClient对象
 func (my *Multiple) Append(hc *HttpClient) *Multiple {
@@ -31,25 +54,147 @@ func (my *Multiple) SetClients(clients []*HttpClient) *Multiple {
 	return my
 }
 
+// SetSession 设置http会话：未配置自身Transport（自定义Transport、会话、证书、分阶段超时）的httpClient对象发送时使用该会话，发送后还原
+func (my *Multiple) SetSession(session *HttpSession) *Multiple {
+	my.session = session
+
+	return my
+}
+
+// SetContext 设置上下文：上下文取消时中断整个批次
+func (my *Multiple) SetContext(ctx context.Context) *Multiple {
+	my.ctx = ctx
+
+	return my
+}
+
+// SetConcurrency 设置最大并发数：0表示不限制
+func (my *Multiple) SetConcurrency(concurrency int) *Multiple {
+	my.concurrency = concurrency
+
+	return my
+}
+
+// SetFailFast 设置快速失败：任意请求出错后取消其余请求
+func (my *Multiple) SetFailFast(failFast bool) *Multiple {
+	my.failFast = failFast
+
+	return my
+}
+
+// SetOnProgress 设置进度回调
+func (my *Multiple) SetOnProgress(onProgress MultipleProgress) *Multiple {
+	my.onProgress = onProgress
+
+	return my
+}
+
 // Send 批量发送
 func (my *Multiple) Send() *Multiple {
-	if len(my.clients) > 0 {
-		var wg sync.WaitGroup
-		wg.Add(len(my.clients))
+	my.results = make([]*MultipleResult, len(my.clients))
+	if len(my.clients) == 0 {
+		return my
+	}
 
-		for _, client := range my.clients {
-			go func(client *HttpClient) {
-				defer wg.Done()
+	ctx := my.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-				client.Send()
-			}(client)
+	concurrency := my.concurrency
+	if concurrency <= 0 || concurrency > len(my.clients) {
+		concurrency = len(my.clients)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		done     int
+		indexes  = make(chan int)
+		complete = func(result *MultipleResult) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			my.results[result.Index] = result
+			done++
+			if my.failFast && result.Err != nil && !result.Skipped {
+				cancel()
+			}
+			if my.onProgress != nil {
+				my.onProgress(done, len(my.clients), result)
+			}
 		}
+	)
+
+	wg.Add(concurrency)
+	for range concurrency {
+		go func() {
+			defer wg.Done()
 
-		wg.Wait()
+			for idx := range indexes {
+				complete(my.sendOne(ctx, idx))
+			}
+		}()
 	}
 
+	for idx := range my.clients {
+		indexes <- idx
+	}
+	close(indexes)
+
+	wg.Wait()
+
 	return my
 }
 
+// sendOne 发送批次中的单个请求：批次已取消时跳过
+func (my *Multiple) sendOne(ctx context.Context, idx int) *MultipleResult {
+	client := my.clients[idx]
+	result := &MultipleResult{Index: idx, Client: client}
+
+	if ctx.Err() != nil {
+		result.Skipped = true
+		result.Err = RequestCanceledErr.New("批量请求已中断")
+		return result
+	}
+
+	// 只有未配置自身Transport的请求使用批次的会话：避免与证书、分阶段超时冲突，发送后还原
+	if my.session != nil && !client.hasOwnTransport() {
+		defer func() { client.session = nil }()
+		client.session = my.session
+	}
+
+	// 批次取消时同时取消该请求，保留请求自身的上下文，发送后还原
+	defer func(ctx context.Context) { client.ctx = ctx }(client.ctx)
+	clientCtx, clientCancel := context.WithCancel(client.GetContext())
+	defer clientCancel()
+	stop := context.AfterFunc(ctx, clientCancel)
+	defer stop()
+
+	start := time.Now()
+	client.SendWithContext(clientCtx)
+	result.Duration = time.Since(start)
+	result.Err = client.Err
+
+	return result
+}
+
 // GetClients 获取链接池
 func (my *Multiple) GetClients() []*HttpClient { return my.clients }
+
+// GetResults 获取按请求顺序排列的结果
+func (my *Multiple) GetResults() []*MultipleResult { return my.results }
+
+// GetErrors 获取按请求顺序排列的错误：成功的请求对应nil
+func (my *Multiple) GetErrors() []error {
+	errs := make([]error, len(my.results))
+	for idx, result := range my.results {
+		if result != nil {
+			errs[idx] = result.Err
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,144 @@
+package httpClient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMultipleSend(t *testing.T) {
+	t.Run("结果按请求顺序排列", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// 越靠前的请求越晚返回
+			idx, _ := strconv.Atoi(r.URL.Query().Get("i"))
+			time.Sleep(time.Duration(5-idx) * 10 * time.Millisecond)
+			_, _ = w.Write([]byte(strconv.Itoa(idx)))
+		}))
+		defer server.Close()
+
+		multiple := MultipleApp.New()
+		for idx := range 5 {
+			multiple.Append(NewGet(fmt.Sprintf("%s/?i=%d", server.URL, idx)))
+		}
+
+		for idx, result := range multiple.Send().GetResults() {
+			if result.Err != nil {
+				t.Fatalf("请求%d失败：%v", idx, result.Err)
+			}
+			if result.Index != idx || result.Client != multiple.GetClients()[idx] {
+				t.Fatalf("结果顺序错误：%d", result.Index)
+			}
+			if got := string(result.Client.GetResponseRawBody()); got != strconv.Itoa(idx) {
+				t.Fatalf("响应错误：%s", got)
+			}
+		}
+	})
+
+	t.Run("并发上限与进度回调", func(t *testing.T) {
+		var active, maxActive atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := active.Add(1)
+			defer active.Add(-1)
+			for {
+				if peak := maxActive.Load(); current <= peak || maxActive.CompareAndSwap(peak, current) {
+					break
+				}
+			}
+			time.Sleep(30 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		var (
+			dones  []int
+			totals []int
+		)
+		multiple := MultipleApp.New().SetConcurrency(3).SetOnProgress(func(done, total int, result *MultipleResult) {
+			dones = append(dones, done)
+			totals = append(totals, total)
+		})
+		for range 8 {
+			multiple.Append(NewGet(server.URL))
+		}
+		multiple.Send()
+
+		if peak := maxActive.Load(); peak != 3 {
+			t.Fatalf("最大并发数错误：%d", peak)
+		}
+		if !slices.Equal(dones, []int{1, 2, 3, 4, 5, 6, 7, 8}) {
+			t.Fatalf("完成数量错误：%v", dones)
+		}
+		if slices.ContainsFunc(totals, func(total int) bool { return total != 8 }) {
+			t.Fatalf("总数量错误：%v", totals)
+		}
+	})
+
+	t.Run("快速失败取消其余请求", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("slow") != "" {
+				<-r.Context().Done()
+			}
+		}))
+		defer server.Close()
+
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		multiple := MultipleApp.New().SetConcurrency(2).SetFailFast(true).
+			Append(NewGet(server.URL + "/?slow=1")).
+			Append(NewGet(closed.URL)).
+			Append(NewGet(server.URL)).
+			Append(NewGet(server.URL))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			multiple.Send()
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("快速失败应取消进行中的请求")
+		}
+
+		errs := multiple.GetErrors()
+		if !errors.Is(errs[1], &RequestNetworkErr) {
+			t.Fatalf("失败请求的错误错误：%v", errs[1])
+		}
+		if !errors.Is(errs[0], &RequestCanceledErr) {
+			t.Fatalf("进行中的请求应被取消：%v", errs[0])
+		}
+		for _, result := range multiple.GetResults()[2:] {
+			if !result.Skipped || !errors.Is(result.Err, &RequestCanceledErr) {
+				t.Fatalf("其余请求应跳过：%+v", result)
+			}
+		}
+	})
+
+	t.Run("会话只用于未配置Transport的请求", func(t *testing.T) {
+		var direct, proxied atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { direct.Add(1) }))
+		defer server.Close()
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { proxied.Add(1) }))
+		defer proxy.Close()
+
+		plain := NewGet(server.URL)
+		withTimeout := NewGet(server.URL).SetResponseHeaderTimeout(time.Second)
+		MultipleApp.New().SetSession(HttpSessionApp.New().SetProxy(proxy.URL)).Append(plain).Append(withTimeout).Send()
+
+		if plain.Err != nil || withTimeout.Err != nil {
+			t.Fatalf("请求失败：%v，%v", plain.Err, withTimeout.Err)
+		}
+		if proxied.Load() != 1 || direct.Load() != 1 {
+			t.Fatalf("会话使用错误：经过会话%d，直接发送%d", proxied.Load(), direct.Load())
+		}
+		if plain.GetSession() != nil || withTimeout.GetSession() != nil {
+			t.Fatal("发送后不应修改请求的会话")
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package httpClient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+type (
+	// RecordMode 录制回放模式
+	RecordMode string
+
+	// RecordTransport 录制回放Transport：录制模式下转发真实请求并记录交互，回放模式下从golden文件中按顺序返回匹配的响应
+	RecordTransport struct {
+		lock          sync.Mutex
+		mode          RecordMode
+		filename      string
+		base          http.RoundTripper
+		redactHeaders []string
+		cassette      *Cassette
+		used          []bool
+	}
+
+	// Cassette golden文件内容
+	Cassette struct {
+		Interactions []*Interaction `json:"interactions"`
+	}
+
+	// Interaction 一次请求响应交互
+	Interaction struct {
+		Request  *RecordedRequest  `json:"request"`
+		Response *RecordedResponse `json:"response"`
+	}
+
+	// RecordedRequest 录制的请求
+	RecordedRequest struct {
+		Method       string      `json:"method"`
+		Url          string      `json:"url"`
+		Header       http.Header `json:"header,omitempty"`
+		Body         string      `json:"body,omitempty"`
+		BodyEncoding string      `json:"bodyEncoding,omitempty"`
+	}
+
+	// RecordedResponse 录制的响应
+	RecordedResponse struct {
+		StatusCode   int         `json:"statusCode"`
+		Header       http.Header `json:"header,omitempty"`
+		Body         string      `json:"body,omitempty"`
+		BodyEncoding string      `json:"bodyEncoding,omitempty"`
+	}
+)
+
+const (
+	RecordModeRecord RecordMode = "record" // 录制：转发真实请求
+	RecordModeReplay RecordMode = "replay" // 回放：只使用golden文件
+	RecordModeAuto   RecordMode = "auto"   // 自动：golden文件存在时回放，否则录制
+)
+
+var RecordTransportApp RecordTransport
+
+// New 实例化：录制回放Transport
+func (*RecordTransport) New(filename string, mode RecordMode) *RecordTransport {
+	recorder := &RecordTransport{
+		mode:          mode,
+		filename:      filename,
+		base:          http.DefaultTransport,
+		redactHeaders: []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"},
+		cassette:      &Cassette{},
+	}
+
+	if mode == RecordModeAuto {
+		if _, err := os.Stat(filename); err == nil {
+			recorder.mode = RecordModeReplay
+		} else {
+			recorder.mode = RecordModeRecord
+		}
+	}
+
+	return recorder
+}
+
+// SetBase 设置录制时使用的真实Transport
+func (my *RecordTransport) SetBase(base http.RoundTripper) *RecordTransport {
+	my.base = base
+
+	return my
+}
+
+// SetRedactHeaders 设置录制时需要脱敏的请求头、响应头
+func (my *RecordTransport) SetRedactHeaders(headers ...string) *RecordTransport {
+	my.redactHeaders = headers
+
+	return my
+}
+
+// GetMode 获取当前模式
+func (my *RecordTransport) GetMode() RecordMode { return my.mode }
+
+// Load 读取golden文件
+func (my *RecordTransport) Load() error {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	return my.load()
+}
+
+// load 读取golden文件（调用方需持有锁）
+func (my *RecordTransport) load() error {
+	content, err := os.ReadFile(my.filename)
+	if err != nil {
+		return RecordErr.Wrap(err)
+	}
+
+	cassette := &Cassette{}
+	if err = json.Unmarshal(content, cassette); err != nil {
+		return RecordErr.Wrap(err)
+	}
+
+	my.cassette = cassette
+	my.used = make([]bool, len(cassette.Interactions))
+
+	return nil
+}
+
+// Save 保存golden文件
+func (my *RecordTransport) Save() error {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	content, err := json.MarshalIndent(my.cassette, "", "  ")
+	if err != nil {
+		return RecordErr.Wrap(err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(my.filename), 0755); err != nil {
+		return RecordErr.Wrap(err)
+	}
+
+	if err = os.WriteFile(my.filename, content, 0644); err != nil {
+		return RecordErr.Wrap(err)
+	}
+
+	return nil
+}
+
+// RoundTrip 实现http.RoundTripper
+func (my *RecordTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		if requestBody, err = io.ReadAll(request.Body); err != nil {
+			return nil, RecordErr.Wrap(err)
+		}
+		_ = request.Body.Close()
+		request.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	if my.mode == RecordModeReplay {
+		return my.replay(request, requestBody)
+	}
+
+	return my.record(request, requestBody)
+}
+
+// record 转发真实请求并记录交互
+func (my *RecordTransport) record(request *http.Request, requestBody []byte) (*http.Response, error) {
+	response, err := my.base.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, RecordErr.Wrap(err)
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	recordedRequest := &RecordedRequest{Method: request.Method, Url: request.URL.String(), Header: my.redact(request.Header)}
+	recordedRequest.Body, recordedRequest.BodyEncoding = encodeBody(requestBody)
+
+	recordedResponse := &RecordedResponse{StatusCode: response.StatusCode, Header: my.redact(response.Header)}
+	recordedResponse.Body, recordedResponse.BodyEncoding = encodeBody(responseBody)
+
+	my.lock.Lock()
+	my.cassette.Interactions = append(my.cassette.Interactions, &Interaction{Request: recordedRequest, Response: recordedResponse})
+	my.used = append(my.used, true)
+	my.lock.Unlock()
+
+	return response, nil
+}
+
+// replay 按方法、url、请求体匹配第一个未使用的交互
+func (my *RecordTransport) replay(request *http.Request, requestBody []byte) (*http.Response, error) {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	if my.used == nil {
+		if err := my.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	for idx, interaction := range my.cassette.Interactions {
+		if my.used[idx] || interaction.Request.Method != request.Method || interaction.Request.Url != request.URL.String() {
+			continue
+		}
+
+		body, err := decodeBody(interaction.Request.Body, interaction.Request.BodyEncoding)
+		if err != nil {
+			return nil, RecordErr.Wrap(err)
+		}
+		if !bytes.Equal(body, requestBody) {
+			continue
+		}
+
+		responseBody, err := decodeBody(interaction.Response.Body, interaction.Response.BodyEncoding)
+		if err != nil {
+			return nil, RecordErr.Wrap(err)
+		}
+
+		my.used[idx] = true
+
+		return newMockResponse(request, interaction.Response.StatusCode, interaction.Response.Header.Clone(), responseBody), nil
+	}
+
+	return nil, RecordErr.New(fmt.Sprintf("没有匹配的录制记录：%s %s", request.Method, request.URL.String()))
+}
+
+// redact 请求头、响应头脱敏
+func (my *RecordTransport) redact(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, key := range my.redactHeaders {
+		if redacted.Get(key) != "" {
+			redacted.Set(key, "REDACTED")
+		}
+	}
+
+	return redacted
+}
+
+// encodeBody 编码请求体、响应体：非utf8内容使用base64
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+// decodeBody 解码请求体、响应体
+func decodeBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(body)
+	default:
+		return nil, errors.New("不支持的编码：" + encoding)
+	}
+}
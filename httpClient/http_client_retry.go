@@ -0,0 +1,253 @@
+package httpClient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	// RetryPolicy 重试策略
+	RetryPolicy struct {
+		maxAttempts        int
+		baseDelay          time.Duration
+		maxDelay           time.Duration
+		statusCodes        map[int]struct{}
+		retryOnTimeout     bool
+		retryOnNetwork     bool
+		retryNonIdempotent bool
+		respectRetryAfter  bool
+	}
+
+	// SendAttempt 单次请求诊断信息
+	SendAttempt struct {
+		Number     int           // 第几次请求，从1开始
+		StatusCode int           // 响应状态码，请求失败时为0
+		Err        error         // 请求错误
+		Duration   time.Duration // 请求耗时
+		Wait       time.Duration // 下次重试前的等待时间，最后一次请求为0
+	}
+)
+
+var (
+	RetryPolicyApp RetryPolicy
+
+	// idempotentMethods 幂等请求方法
+	idempotentMethods = map[string]struct{}{
+		http.MethodGet:     {},
+		http.MethodHead:    {},
+		http.MethodOptions: {},
+		http.MethodTrace:   {},
+		http.MethodPut:     {},
+		http.MethodDelete:  {},
+	}
+)
+
+// New 实例化：重试策略
+//
+// 默认：最多请求3次；退避100ms起，最大10s；对429、502、503、504状态码以及超时、网络错误重试；仅重试幂等请求；遵循Retry-After响应头
+func (*RetryPolicy) New() *RetryPolicy {
+	return &RetryPolicy{
+		maxAttempts: 3,
+		baseDelay:   100 * time.Millisecond,
+		maxDelay:    10 * time.Second,
+		statusCodes: map[int]struct{}{
+			http.StatusTooManyRequests:    {},
+			http.StatusBadGateway:         {},
+			http.StatusServiceUnavailable: {},
+			http.StatusGatewayTimeout:     {},
+		},
+		retryOnTimeout:    true,
+		retryOnNetwork:    true,
+		respectRetryAfter: true,
+	}
+}
+
+// SetMaxAttempts 设置最多请求次数（包含第一次请求）
+func (my *RetryPolicy) SetMaxAttempts(maxAttempts int) *RetryPolicy {
+	my.maxAttempts = maxAttempts
+
+	return my
+}
+
+// SetBackoff 设置指数退避的初始间隔和最大间隔
+func (my *RetryPolicy) SetBackoff(baseDelay, maxDelay time.Duration) *RetryPolicy {
+	my.baseDelay = baseDelay
+	my.maxDelay = maxDelay
+
+	return my
+}
+
+// SetStatusCodes 设置需要重试的响应状态码
+func (my *RetryPolicy) SetStatusCodes(statusCodes ...int) *RetryPolicy {
+	my.statusCodes = make(map[int]struct{}, len(statusCodes))
+	for _, statusCode := range statusCodes {
+		my.statusCodes[statusCode] = struct{}{}
+	}
+
+	return my
+}
+
+// SetRetryOnTimeout 设置超时错误是否重试
+func (my *RetryPolicy) SetRetryOnTimeout(retryOnTimeout bool) *RetryPolicy {
+	my.retryOnTimeout = retryOnTimeout
+
+	return my
+}
+
+// SetRetryOnNetwork 设置网络错误是否重试
+func (my *RetryPolicy) SetRetryOnNetwork(retryOnNetwork bool) *RetryPolicy {
+	my.retryOnNetwork = retryOnNetwork
+
+	return my
+}
+
+// SetRetryNonIdempotent 设置非幂等请求（POST、PATCH等）是否重试
+func (my *RetryPolicy) SetRetryNonIdempotent(retryNonIdempotent bool) *RetryPolicy {
+	my.retryNonIdempotent = retryNonIdempotent
+
+	return my
+}
+
+// SetRespectRetryAfter 设置是否遵循Retry-After响应头
+func (my *RetryPolicy) SetRespectRetryAfter(respectRetryAfter bool) *RetryPolicy {
+	my.respectRetryAfter = respectRetryAfter
+
+	return my
+}
+
+// shouldRetry 判断是否需要重试
+func (my *RetryPolicy) shouldRetry(hc *HttpClient) bool {
+	if !my.retryNonIdempotent && !isIdempotent(hc.request) {
+		return false
+	}
+
+	if hc.Err != nil {
+		switch {
+		case errors.Is(hc.Err, &RequestTimeoutErr):
+			return my.retryOnTimeout
+		case errors.Is(hc.Err, &RequestNetworkErr):
+			return my.retryOnNetwork
+		default:
+			return false
+		}
+	}
+
+	if hc.response != nil {
+		_, ok := my.statusCodes[hc.response.StatusCode]
+		return ok
+	}
+
+	return false
+}
+
+// wait 计算下次重试前的等待时间：优先使用Retry-After，否则使用带抖动的指数退避，均不超过最大间隔
+func (my *RetryPolicy) wait(number int, response *http.Response) time.Duration {
+	if my.respectRetryAfter && response != nil {
+		if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+			return min(retryAfter, my.maxDelay)
+		}
+	}
+
+	if my.baseDelay <= 0 {
+		return 0
+	}
+
+	delay := my.baseDelay << (number - 1)
+	if delay <= 0 || delay > my.maxDelay {
+		delay = my.maxDelay
+	}
+
+	// 抖动：在[delay/2, delay)之间随机
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// isIdempotent 判断请求是否幂等：幂等方法或携带Idempotency-Key请求头
+func isIdempotent(request *http.Request) bool {
+	if request == nil {
+		return false
+	}
+
+	if _, ok := idempotentMethods[request.Method]; ok {
+		return true
+	}
+
+	return request.Header.Get("Idempotency-Key") != ""
+}
+
+// parseRetryAfter 解析Retry-After响应头：秒数或HTTP日期
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+
+	return 0, false
+}
+
+// SetRetryPolicy 设置重试策略：nil表示不重试
+func (my *HttpClient) SetRetryPolicy(retryPolicy *RetryPolicy) *HttpClient {
+	my.retryPolicy = retryPolicy
+
+	return my
+}
+
+// GetAttempts 获取最近一次发送的每次请求诊断信息
+func (my *HttpClient) GetAttempts() []*SendAttempt { return my.attempts }
+
+// sendWithRetry 按重试策略发送请求：每次重试使用缓存的请求体重新构建请求体
+func (my *HttpClient) sendWithRetry() *HttpClient {
+	my.attempts = make([]*SendAttempt, 0, max(my.retryPolicy.maxAttempts, 1))
+
+	for number := 1; ; number++ {
+		if number > 1 {
+			my.Err = nil
+			my.response = nil
+			my.responseBody = []byte{}
+			my.request.Body = io.NopCloser(bytes.NewReader(my.requestBody))
+		}
+
+		start := time.Now()
+		my.sendOnce()
+
+		attempt := &SendAttempt{Number: number, Err: my.Err, Duration: time.Since(start)}
+		if my.response != nil {
+			attempt.StatusCode = my.response.StatusCode
+		}
+		my.attempts = append(my.attempts, attempt)
+
+		if number >= my.retryPolicy.maxAttempts || my.request == nil || !my.retryPolicy.shouldRetry(my) {
+			return my
+		}
+
+		attempt.Wait = my.retryPolicy.wait(number, my.response)
+
+		timer := time.NewTimer(attempt.Wait)
+		select {
+		case <-my.GetContext().Done():
+			timer.Stop()
+			my.Err = classifyRequestError(my.GetContext(), my.GetContext().Err())
+			return my
+		case <-timer.C:
+		}
+	}
+}
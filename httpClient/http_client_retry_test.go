@@ -0,0 +1,162 @@
+package httpClient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// retryServer 按顺序返回状态码的测试服务：超出后返回200，并记录每次收到的请求体
+type retryServer struct {
+	*httptest.Server
+	lock   sync.Mutex
+	codes  []int
+	header http.Header
+	bodies []string
+}
+
+func newRetryServer(t *testing.T, header http.Header, codes ...int) *retryServer {
+	server := &retryServer{codes: codes, header: header}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		server.lock.Lock()
+		defer server.lock.Unlock()
+
+		server.bodies = append(server.bodies, string(body))
+		if len(server.codes) == 0 {
+			return
+		}
+		for key, values := range server.header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(server.codes[0])
+		server.codes = server.codes[1:]
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// statusCodes 获取每次请求的状态码
+func statusCodes(attempts []*SendAttempt) []int {
+	codes := make([]int, len(attempts))
+	for idx, attempt := range attempts {
+		codes[idx] = attempt.StatusCode
+	}
+
+	return codes
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := func() *RetryPolicy { return RetryPolicyApp.New().SetBackoff(time.Millisecond, 10*time.Millisecond) }
+
+	tests := []struct {
+		name   string
+		client func(url string) *HttpClient
+		codes  []int
+		want   []int
+	}{
+		{"5xx重试", func(url string) *HttpClient { return NewGet(url).SetRetryPolicy(policy()) }, []int{502, 503}, []int{502, 503, 200}},
+		{"429重试", func(url string) *HttpClient { return NewGet(url).SetRetryPolicy(policy()) }, []int{429}, []int{429, 200}},
+		{"最多请求次数", func(url string) *HttpClient { return NewGet(url).SetRetryPolicy(policy()) }, []int{503, 503, 503}, []int{503, 503, 503}},
+		{"默认不重试500", func(url string) *HttpClient { return NewGet(url).SetRetryPolicy(policy()) }, []int{500}, []int{500}},
+		{"自定义状态码", func(url string) *HttpClient { return NewGet(url).SetRetryPolicy(policy().SetStatusCodes(500)) }, []int{500}, []int{500, 200}},
+		{"非幂等请求不重试", func(url string) *HttpClient { return NewPost(url).SetRetryPolicy(policy()) }, []int{503}, []int{503}},
+		{"非幂等请求开启重试", func(url string) *HttpClient { return NewPost(url).SetRetryPolicy(policy().SetRetryNonIdempotent(true)) }, []int{503}, []int{503, 200}},
+		{"携带Idempotency-Key", func(url string) *HttpClient {
+			return NewPost(url).AddHeaders(map[string][]string{"Idempotency-Key": {"k"}}).SetRetryPolicy(policy())
+		}, []int{503}, []int{503, 200}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newRetryServer(t, nil, test.codes...)
+
+			hc := test.client(server.URL).Send()
+			if hc.Err != nil {
+				t.Fatalf("请求失败：%v", hc.Err)
+			}
+
+			attempts := hc.GetAttempts()
+			if got := statusCodes(attempts); !slices.Equal(got, test.want) {
+				t.Fatalf("请求状态码错误：%v", got)
+			}
+			for idx, attempt := range attempts {
+				if attempt.Number != idx+1 {
+					t.Fatalf("请求序号错误：%d", attempt.Number)
+				}
+				if last := idx == len(attempts)-1; last != (attempt.Wait == 0) {
+					t.Fatalf("第%d次请求的等待时间错误：%v", attempt.Number, attempt.Wait)
+				}
+			}
+			if hc.GetResponse().StatusCode != test.want[len(test.want)-1] {
+				t.Fatalf("响应应为最后一次请求：%d", hc.GetResponse().StatusCode)
+			}
+		})
+	}
+
+	t.Run("每次重试重新发送请求体", func(t *testing.T) {
+		server := newRetryServer(t, nil, 503, 503)
+
+		hc := NewPost(server.URL).SetJsonBody(map[string]string{"name": "test"}).SetRetryPolicy(policy().SetRetryNonIdempotent(true)).Send()
+		if hc.Err != nil {
+			t.Fatalf("请求失败：%v", hc.Err)
+		}
+		if want := `{"name":"test"}`; !slices.Equal(server.bodies, []string{want, want, want}) {
+			t.Fatalf("服务端收到的请求体错误：%q", server.bodies)
+		}
+	})
+
+	t.Run("网络错误重试", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		hc := NewGet(closed.URL).SetRetryPolicy(policy()).Send()
+		if !errors.Is(hc.Err, &RequestNetworkErr) {
+			t.Fatalf("期望RequestNetworkErr，实际：%v", hc.Err)
+		}
+		if attempts := hc.GetAttempts(); len(attempts) != 3 || !errors.Is(attempts[0].Err, &RequestNetworkErr) {
+			t.Fatalf("网络错误应重试：%d", len(attempts))
+		}
+	})
+
+	t.Run("Retry-After秒数", func(t *testing.T) {
+		server := newRetryServer(t, http.Header{"Retry-After": {"1"}}, 503)
+
+		start := time.Now()
+		hc := NewGet(server.URL).SetRetryPolicy(policy().SetBackoff(time.Millisecond, 10*time.Second)).Send()
+		if hc.Err != nil {
+			t.Fatalf("请求失败：%v", hc.Err)
+		}
+		if wait := hc.GetAttempts()[0].Wait; wait != time.Second {
+			t.Fatalf("应按Retry-After等待：%v", wait)
+		}
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Fatalf("重试前应等待：%v", elapsed)
+		}
+	})
+
+	t.Run("Retry-After日期", func(t *testing.T) {
+		header := func(value string) *http.Response { return &http.Response{Header: http.Header{"Retry-After": {value}}} }
+		retryPolicy := policy().SetBackoff(time.Millisecond, 2*time.Hour)
+
+		if wait := retryPolicy.wait(1, header(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))); wait < 59*time.Minute || wait > time.Hour {
+			t.Fatalf("应按Retry-After日期等待：%v", wait)
+		}
+		if wait := retryPolicy.wait(1, header(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))); wait != 0 {
+			t.Fatalf("Retry-After日期已过时不应等待：%v", wait)
+		}
+		if wait := policy().wait(1, header(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))); wait != 10*time.Millisecond {
+			t.Fatalf("等待时间不应超过最大间隔：%v", wait)
+		}
+		if wait := retryPolicy.SetRespectRetryAfter(false).wait(1, header("3600")); wait >= time.Millisecond {
+			t.Fatalf("不遵循Retry-After时应使用退避间隔：%v", wait)
+		}
+	})
+}
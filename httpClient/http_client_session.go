@@ -0,0 +1,163 @@
+package httpClient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// HttpSession http会话：持有一个可复用的Transport，多个HttpClient共享同一个会话时复用链接
+//
+// 会话的配置方法需要在发送请求之前调用，发送请求过程中修改配置不是并发安全的
+type HttpSession struct {
+	Err       error
+	transport *http.Transport
+	dialer    *net.Dialer
+}
+
+var HttpSessionApp HttpSession
+
+// New 实例化：http会话
+//
+// 默认：最大空闲链接100，每个主机最大空闲链接32，空闲链接超时90s，开启HTTP/2，使用环境变量中的代理
+func (*HttpSession) New() *HttpSession {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 32
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.ForceAttemptHTTP2 = true
+	transport.TLSClientConfig = &tls.Config{}
+
+	return &HttpSession{transport: transport, dialer: dialer}
+}
+
+// SetMaxIdleConns 设置最大空闲链接数
+func (my *HttpSession) SetMaxIdleConns(maxIdleConns int) *HttpSession {
+	my.transport.MaxIdleConns = maxIdleConns
+
+	return my
+}
+
+// SetMaxIdleConnsPerHost 设置每个主机最大空闲链接数
+func (my *HttpSession) SetMaxIdleConnsPerHost(maxIdleConnsPerHost int) *HttpSession {
+	my.transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+
+	return my
+}
+
+// SetMaxConnsPerHost 设置每个主机最大链接数：0表示不限制
+func (my *HttpSession) SetMaxConnsPerHost(maxConnsPerHost int) *HttpSession {
+	my.transport.MaxConnsPerHost = maxConnsPerHost
+
+	return my
+}
+
+// SetIdleConnTimeout 设置空闲链接超时
+func (my *HttpSession) SetIdleConnTimeout(timeout time.Duration) *HttpSession {
+	my.transport.IdleConnTimeout = timeout
+
+	return my
+}
+
+// SetDialTimeout 设置建立链接超时
+func (my *HttpSession) SetDialTimeout(timeout time.Duration) *HttpSession {
+	my.dialer.Timeout = timeout
+
+	return my
+}
+
+// SetTLSHandshakeTimeout 设置TLS握手超时
+func (my *HttpSession) SetTLSHandshakeTimeout(timeout time.Duration) *HttpSession {
+	my.transport.TLSHandshakeTimeout = timeout
+
+	return my
+}
+
+// SetResponseHeaderTimeout 设置等待响应头超时
+func (my *HttpSession) SetResponseHeaderTimeout(timeout time.Duration) *HttpSession {
+	my.transport.ResponseHeaderTimeout = timeout
+
+	return my
+}
+
+// SetHttp2 设置是否启用HTTP/2
+func (my *HttpSession) SetHttp2(enable bool) *HttpSession {
+	my.transport.ForceAttemptHTTP2 = enable
+	if enable {
+		my.transport.TLSNextProto = nil
+	} else {
+		// 非nil的空map表示禁用HTTP/2
+		my.transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return my
+}
+
+// SetProxy 设置代理地址：空字符串表示不使用代理
+func (my *HttpSession) SetProxy(proxyUrl string) *HttpSession {
+	if proxyUrl == "" {
+		my.transport.Proxy = nil
+		return my
+	}
+
+	u, err := url.Parse(proxyUrl)
+	if err != nil {
+		my.Err = err
+		return my
+	}
+	my.transport.Proxy = http.ProxyURL(u)
+
+	return my
+}
+
+// SetRootCA 设置根证书：用于校验服务端证书
+func (my *HttpSession) SetRootCA(filenames ...string) *HttpSession {
+	certPool := x509.NewCertPool()
+	for _, filename := range filenames {
+		cert, err := os.ReadFile(filename)
+		if err != nil {
+			my.Err = GenerateCertErr.Wrap(err)
+			return my
+		}
+
+		if !certPool.AppendCertsFromPEM(cert) {
+			my.Err = GenerateCertErr.New(filename)
+			return my
+		}
+	}
+	my.transport.TLSClientConfig.RootCAs = certPool
+
+	return my
+}
+
+// SetClientCert 设置客户端证书：用于双向TLS认证
+func (my *HttpSession) SetClientCert(certFile, keyFile string) *HttpSession {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		my.Err = GenerateCertErr.Wrap(err)
+		return my
+	}
+	my.transport.TLSClientConfig.Certificates = append(my.transport.TLSClientConfig.Certificates, cert)
+
+	return my
+}
+
+// SetInsecureSkipVerify 设置是否跳过服务端证书校验
+func (my *HttpSession) SetInsecureSkipVerify(skip bool) *HttpSession {
+	my.transport.TLSClientConfig.InsecureSkipVerify = skip
+
+	return my
+}
+
+// GetTransport 获取Transport
+func (my *HttpSession) GetTransport() *http.Transport { return my.transport }
+
+// CloseIdleConnections 关闭空闲链接
+func (my *HttpSession) CloseIdleConnections() { my.transport.CloseIdleConnections() }
@@ -0,0 +1,37 @@
+package httpClient
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSessionWithClientTransportSettings(t *testing.T) {
+	cert := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(cert, []byte("-----BEGIN CERTIFICATE-----"), 0644); err != nil {
+		t.Fatalf("写入证书失败：%v", err)
+	}
+
+	cases := map[string]func(hc *HttpClient) *HttpClient{
+		"SetDialTimeout":           func(hc *HttpClient) *HttpClient { return hc.SetDialTimeout(time.Second) },
+		"SetTLSHandshakeTimeout":   func(hc *HttpClient) *HttpClient { return hc.SetTLSHandshakeTimeout(time.Second) },
+		"SetResponseHeaderTimeout": func(hc *HttpClient) *HttpClient { return hc.SetResponseHeaderTimeout(time.Second) },
+		"SetCert":                  func(hc *HttpClient) *HttpClient { return hc.SetCert(cert) },
+	}
+
+	for name, fn := range cases {
+		hc := fn(NewGet("http://mock/").SetSession(HttpSessionApp.New())).GenerateRequest()
+		if !errors.Is(hc.Err, &GenerateRequestErr) {
+			t.Fatalf("%s：期望GenerateRequestErr，实际：%v", name, hc.Err)
+		}
+	}
+
+	mock := MockTransportApp.New()
+	mock.On(http.MethodGet, "/")
+	if hc := NewGet("http://mock/").SetSession(HttpSessionApp.New().SetDialTimeout(time.Second)).SetTransport(mock).Send(); hc.Err != nil {
+		t.Fatalf("在会话中设置超时应正常发送：%v", hc.Err)
+	}
+}
@@ -0,0 +1,272 @@
+package httpClient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// HttpClientStream 流式响应：逐条回调处理SSE事件、NDJSON记录或原始分块，回调返回前不会继续读取（背压）
+	HttpClientStream struct {
+		httpClient     *HttpClient
+		maxReconnects  int
+		reconnectDelay time.Duration
+		lastEventId    string
+	}
+
+	// SseEvent SSE事件
+	SseEvent struct {
+		Id    string
+		Event string
+		Data  string
+		Retry time.Duration
+	}
+)
+
+// StopStream 回调返回该错误时停止读取流，且不视为错误
+var StopStream = errors.New("停止读取流")
+
+// Stream 使用流式方式读取响应
+func (my *HttpClient) Stream() *HttpClientStream {
+	return &HttpClientStream{httpClient: my, reconnectDelay: 3 * time.Second}
+}
+
+// SetReconnect 设置SSE自动重连：maxReconnects为最大重连次数（小于0表示不限制），delay为默认重连间隔（服务端retry字段优先）
+func (my *HttpClientStream) SetReconnect(maxReconnects int, delay time.Duration) *HttpClientStream {
+	my.maxReconnects = maxReconnects
+	my.reconnectDelay = delay
+
+	return my
+}
+
+// SetLastEventId 设置Last-Event-ID：从指定事件之后继续接收
+func (my *HttpClientStream) SetLastEventId(lastEventId string) *HttpClientStream {
+	my.lastEventId = lastEventId
+
+	return my
+}
+
+// GetLastEventId 获取最后收到的事件id
+func (my *HttpClientStream) GetLastEventId() string { return my.lastEventId }
+
+// open 发送请求并检查响应状态：返回本次请求的上下文和取消方法
+func (my *HttpClientStream) open(client *http.Client) (context.Context, context.CancelCauseFunc, error) {
+	ctx, cancel := my.httpClient.do(client)
+	if my.httpClient.Err != nil {
+		return ctx, cancel, my.httpClient.Err
+	}
+
+	if my.httpClient.response.StatusCode < http.StatusOK || my.httpClient.response.StatusCode >= http.StatusMultipleChoices {
+		_ = my.httpClient.response.Body.Close()
+		return ctx, cancel, StreamErr.New(my.httpClient.response.Status)
+	}
+
+	return ctx, cancel, nil
+}
+
+// EachChunk 逐块读取原始响应体
+func (my *HttpClientStream) EachChunk(fn func(chunk []byte) error) *HttpClient {
+	defer func() { my.httpClient.isReady = false }()
+
+	client := my.httpClient.beforeSend()
+	if my.httpClient.Err != nil {
+		return my.httpClient
+	}
+
+	ctx, cancel, err := my.open(client)
+	defer cancel(nil)
+	if err != nil {
+		my.httpClient.Err = err
+		return my.httpClient
+	}
+	defer func() { _ = my.httpClient.response.Body.Close() }()
+
+	buffer := make([]byte, 32*1024)
+	for {
+		n, readErr := my.httpClient.response.Body.Read(buffer)
+		if n > 0 {
+			if err = fn(buffer[:n]); err != nil {
+				my.httpClient.Err = stopStreamError(err)
+				return my.httpClient
+			}
+		}
+
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) {
+				my.httpClient.Err = classifyReadError(ctx, readErr)
+			}
+			return my.httpClient
+		}
+	}
+}
+
+// EachNdjson 逐行读取NDJSON记录：跳过空行，line不包含换行符
+func (my *HttpClientStream) EachNdjson(fn func(line []byte) error) *HttpClient {
+	defer func() { my.httpClient.isReady = false }()
+
+	client := my.httpClient.beforeSend()
+	if my.httpClient.Err != nil {
+		return my.httpClient
+	}
+
+	// 请求头设置在已生成的请求上：请求可能在调用前已经生成
+	if my.httpClient.request.Header.Get("Accept") == "" {
+		my.httpClient.request.Header.Set("Accept", "application/x-ndjson")
+	}
+
+	ctx, cancel, err := my.open(client)
+	defer cancel(nil)
+	if err != nil {
+		my.httpClient.Err = err
+		return my.httpClient
+	}
+	defer func() { _ = my.httpClient.response.Body.Close() }()
+
+	reader := bufio.NewReader(my.httpClient.response.Body)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if line = bytes.TrimRight(line, "\r\n"); len(bytes.TrimSpace(line)) > 0 {
+			if err = fn(line); err != nil {
+				my.httpClient.Err = stopStreamError(err)
+				return my.httpClient
+			}
+		}
+
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) {
+				my.httpClient.Err = classifyReadError(ctx, readErr)
+			}
+			return my.httpClient
+		}
+	}
+}
+
+// EachSse 逐个读取SSE事件：连接断开时按重连设置携带Last-Event-ID自动重连
+func (my *HttpClientStream) EachSse(fn func(event *SseEvent) error) *HttpClient {
+	defer func() { my.httpClient.isReady = false }()
+
+	client := my.httpClient.beforeSend()
+	if my.httpClient.Err != nil {
+		return my.httpClient
+	}
+
+	// 请求头设置在已生成的请求上：请求可能在调用前已经生成
+	my.httpClient.request.Header.Set("Accept", "text/event-stream")
+	my.httpClient.request.Header.Set("Cache-Control", "no-cache")
+
+	for reconnects := 0; ; reconnects++ {
+		if reconnects > 0 {
+			my.httpClient.Err = nil
+			my.httpClient.request.Body = io.NopCloser(bytes.NewReader(my.httpClient.requestBody))
+		}
+		if my.lastEventId != "" {
+			my.httpClient.request.Header.Set("Last-Event-ID", my.lastEventId)
+		}
+
+		retry, reconnect, err := my.readSse(client, fn)
+		if !reconnect || (my.maxReconnects >= 0 && reconnects >= my.maxReconnects) {
+			my.httpClient.Err = err
+			return my.httpClient
+		}
+
+		timer := time.NewTimer(retry)
+		select {
+		case <-my.httpClient.GetContext().Done():
+			timer.Stop()
+			my.httpClient.Err = classifyRequestError(my.httpClient.GetContext(), my.httpClient.GetContext().Err())
+			return my.httpClient
+		case <-timer.C:
+		}
+	}
+}
+
+// readSse 读取一次SSE连接：返回重连间隔、是否可以重连以及错误
+func (my *HttpClientStream) readSse(client *http.Client, fn func(event *SseEvent) error) (time.Duration, bool, error) {
+	retry := my.reconnectDelay
+
+	ctx, cancel, err := my.open(client)
+	defer cancel(nil)
+	if err != nil {
+		// 服务端明确拒绝时不重连
+		return retry, !errors.Is(err, &StreamErr) && !errors.Is(err, &RequestCanceledErr), err
+	}
+	defer func() { _ = my.httpClient.response.Body.Close() }()
+
+	// 204表示服务端要求不再重连
+	if my.httpClient.response.StatusCode == http.StatusNoContent {
+		return retry, false, nil
+	}
+
+	var (
+		reader = bufio.NewReader(my.httpClient.response.Body)
+		event  = &SseEvent{}
+		data   strings.Builder
+	)
+
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && line == "" {
+			if errors.Is(readErr, io.EOF) {
+				return retry, true, nil
+			}
+			err = classifyReadError(ctx, readErr)
+			return retry, !errors.Is(err, &RequestCanceledErr), err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		// 空行：派发事件
+		if line == "" {
+			if data.Len() > 0 {
+				event.Id = my.lastEventId
+				event.Data = strings.TrimSuffix(data.String(), "\n")
+				if err = fn(event); err != nil {
+					return retry, false, stopStreamError(err)
+				}
+			}
+			event, data = &SseEvent{}, strings.Builder{}
+			continue
+		}
+
+		// 注释
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				my.lastEventId = value
+			}
+		case "retry":
+			if milliseconds, e := strconv.Atoi(value); e == nil && milliseconds >= 0 {
+				retry = time.Duration(milliseconds) * time.Millisecond
+				my.reconnectDelay = retry
+				event.Retry = retry
+			}
+		}
+	}
+}
+
+// stopStreamError 回调错误处理：StopStream不视为错误
+func stopStreamError(err error) error {
+	if errors.Is(err, StopStream) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,144 @@
+package httpClient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamSse(t *testing.T) {
+	var (
+		lock         sync.Mutex
+		accepts      []string
+		lastEventIds []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		accepts = append(accepts, r.Header.Get("Accept"))
+		lastEventIds = append(lastEventIds, r.Header.Get("Last-Event-ID"))
+		lock.Unlock()
+
+		// 按Last-Event-ID继续发送：每次链接发送一个事件后断开，全部发送后返回204停止重连
+		switch r.Header.Get("Last-Event-ID") {
+		case "":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, ": 注释\nevent: message\nid: 1\nretry: 10\ndata: 第一行\ndata: 第二行\n\n")
+		case "1":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, "id: 2\r\ndata:{\"n\":2}\r\n\r\ndata: 缺少空行的事件不派发")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	reset := func() {
+		lock.Lock()
+		defer lock.Unlock()
+		accepts, lastEventIds = nil, nil
+	}
+
+	t.Run("多行数据、id、retry与断线重连", func(t *testing.T) {
+		reset()
+
+		var events []SseEvent
+		hc := NewGet(server.URL).GenerateRequest() // 请求提前生成时也需要设置Accept
+		stream := hc.Stream().SetReconnect(5, time.Second)
+
+		start := time.Now()
+		if stream.EachSse(func(event *SseEvent) error {
+			events = append(events, *event)
+			return nil
+		}); hc.Err != nil {
+			t.Fatalf("读取流失败：%v", hc.Err)
+		}
+
+		want := []SseEvent{
+			{Id: "1", Event: "message", Data: "第一行\n第二行", Retry: 10 * time.Millisecond},
+			{Id: "2", Data: `{"n":2}`},
+		}
+		if !slices.Equal(events, want) {
+			t.Fatalf("事件错误：%+v", events)
+		}
+		if !slices.Equal(lastEventIds, []string{"", "1", "2"}) {
+			t.Fatalf("重连时应携带Last-Event-ID：%q", lastEventIds)
+		}
+		if slices.ContainsFunc(accepts, func(accept string) bool { return accept != "text/event-stream" }) {
+			t.Fatalf("Accept请求头错误：%q", accepts)
+		}
+		if stream.GetLastEventId() != "2" {
+			t.Fatalf("最后的事件id错误：%s", stream.GetLastEventId())
+		}
+		// 服务端retry字段优先于默认重连间隔
+		if elapsed := time.Since(start); elapsed >= time.Second {
+			t.Fatalf("应按服务端retry重连：%v", elapsed)
+		}
+	})
+
+	t.Run("StopStream停止读取且不视为错误", func(t *testing.T) {
+		reset()
+
+		var count int
+		hc := NewGet(server.URL)
+		if hc.Stream().SetReconnect(5, time.Millisecond).EachSse(func(event *SseEvent) error {
+			count++
+			return StopStream
+		}); hc.Err != nil {
+			t.Fatalf("StopStream不应视为错误：%v", hc.Err)
+		}
+
+		if count != 1 || len(lastEventIds) != 1 {
+			t.Fatalf("停止后不应继续读取或重连：事件%d，请求%d", count, len(lastEventIds))
+		}
+	})
+
+	t.Run("回调错误停止读取", func(t *testing.T) {
+		reset()
+
+		hc := NewGet(server.URL)
+		if hc.Stream().SetReconnect(5, time.Millisecond).EachSse(func(event *SseEvent) error {
+			return fmt.Errorf("处理失败")
+		}); hc.Err == nil || hc.Err.Error() != "处理失败" {
+			t.Fatalf("应返回回调错误：%v", hc.Err)
+		}
+		if len(lastEventIds) != 1 {
+			t.Fatalf("回调错误后不应重连：%d", len(lastEventIds))
+		}
+	})
+}
+
+func TestStreamNdjsonAccept(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "%q\n\n{\"n\":1}\n", r.Header.Get("Accept"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		client *HttpClient
+		want   []string
+	}{
+		{"默认Accept", NewGet(server.URL).GenerateRequest(), []string{`"application/x-ndjson"`, `{"n":1}`}},
+		{"保留自定义Accept", NewGet(server.URL).SetHeaderAccept(AcceptJson), []string{`"application/json"`, `{"n":1}`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var lines []string
+			if test.client.Stream().EachNdjson(func(line []byte) error {
+				lines = append(lines, string(line))
+				return nil
+			}); test.client.Err != nil {
+				t.Fatalf("读取流失败：%v", test.client.Err)
+			}
+
+			if !slices.Equal(lines, test.want) {
+				t.Fatalf("读取结果错误：%q", lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package httpClient
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGenerateRequestQueries(t *testing.T) {
+	t.Run("url参数写入请求地址", func(t *testing.T) {
+		hc := NewGet("http://127.0.0.1/api").SetQueries(map[string]string{"a": "1", "b": "x y"}).GenerateRequest()
+		if hc.Err != nil {
+			t.Fatalf("生成请求失败：%v", hc.Err)
+		}
+
+		if got, want := hc.GetRequest().URL.Query(), (url.Values{"a": {"1"}, "b": {"x y"}}); got.Encode() != want.Encode() {
+			t.Fatalf("url参数错误：%s", hc.GetRequest().URL.String())
+		}
+	})
+
+	t.Run("原地址已带参数时追加", func(t *testing.T) {
+		hc := NewGet("http://127.0.0.1/api?c=3").SetQueries(map[string]string{"a": "1"}).GenerateRequest()
+		if hc.Err != nil {
+			t.Fatalf("生成请求失败：%v", hc.Err)
+		}
+
+		if got := hc.GetRequest().URL.RawQuery; got != "c=3&a=1" {
+			t.Fatalf("url参数错误：%s", got)
+		}
+	})
+
+	t.Run("多次生成请求不重复追加", func(t *testing.T) {
+		hc := NewGet("http://127.0.0.1/api").SetQueries(map[string]string{"a": "1"})
+		hc.GenerateRequest()
+		hc.GenerateRequest()
+		if hc.Err != nil {
+			t.Fatalf("生成请求失败：%v", hc.Err)
+		}
+
+		if got := hc.GetRequest().URL.RawQuery; got != "a=1" {
+			t.Fatalf("url参数错误：%s", got)
+		}
+	})
+}
+
+func TestSendCancelAndTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/header":
+			time.Sleep(200 * time.Millisecond)
+		case "/body":
+			// 先返回响应头，响应体停止发送
+			w.Header().Set("Content-Length", "10")
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		default:
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	t.Run("SetContext取消", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		if hc := NewGet(server.URL).SetContext(ctx).Send(); !errors.Is(hc.Err, &RequestCanceledErr) {
+			t.Fatalf("期望RequestCanceledErr，实际：%v", hc.Err)
+		}
+	})
+
+	t.Run("SendWithContext超时", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if hc := NewGet(server.URL).SendWithContext(ctx); !errors.Is(hc.Err, &RequestTimeoutErr) {
+			t.Fatalf("期望RequestTimeoutErr，实际：%v", hc.Err)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		client *HttpClient
+		want   error
+	}{
+		{"总超时", NewGet(server.URL).SetTimeout(20 * time.Millisecond), &RequestTimeoutErr},
+		{"等待响应头超时", NewGet(server.URL + "/header").SetResponseHeaderTimeout(20 * time.Millisecond), &RequestTimeoutErr},
+		{"读取响应体超时", NewGet(server.URL + "/body").SetReadBodyTimeout(20 * time.Millisecond), &RequestTimeoutErr},
+		{"网络错误", NewGet(closed.URL).SetDialTimeout(time.Second), &RequestNetworkErr},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start := time.Now()
+			if hc := test.client.Send(); !errors.Is(hc.Err, test.want) {
+				t.Fatalf("期望%T，实际：%v", test.want, hc.Err)
+			}
+			if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+				t.Fatalf("超时未生效：%v", elapsed)
+			}
+		})
+	}
+}
+
+func TestTransportSettings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	cert := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644); err != nil {
+		t.Fatalf("写入证书失败：%v", err)
+	}
+
+	t.Run("相同配置共享Transport", func(t *testing.T) {
+		first := NewGet(server.URL).SetResponseHeaderTimeout(time.Second).GenerateRequest()
+		second := NewGet(server.URL).SetResponseHeaderTimeout(time.Second).GenerateRequest()
+		other := NewGet(server.URL).SetResponseHeaderTimeout(2 * time.Second).GenerateRequest()
+
+		if first.transport == nil || first.transport != second.transport {
+			t.Fatal("相同配置应共享Transport")
+		}
+		if first.transport == other.transport {
+			t.Fatal("不同配置不应共享Transport")
+		}
+		if NewGet(server.URL).GenerateRequest().transport != nil {
+			t.Fatal("未设置时应使用默认Transport")
+		}
+	})
+
+	t.Run("修改配置后重新获取Transport", func(t *testing.T) {
+		hc := NewGet(server.URL).SetResponseHeaderTimeout(time.Second)
+		if hc.Send(); !errors.Is(hc.Err, &RequestNetworkErr) {
+			t.Fatalf("未设置证书时校验服务端证书应失败：%v", hc.Err)
+		}
+
+		if hc.SetCert(cert).Send(); hc.Err != nil {
+			t.Fatalf("设置证书后应请求成功：%v", hc.Err)
+		}
+		if hc.transport.ResponseHeaderTimeout != time.Second {
+			t.Fatalf("分阶段超时丢失：%v", hc.transport.ResponseHeaderTimeout)
+		}
+	})
+
+	t.Run("无效证书", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "invalid.pem")
+		if err := os.WriteFile(invalid, []byte("-----BEGIN CERTIFICATE-----"), 0644); err != nil {
+			t.Fatalf("写入证书失败：%v", err)
+		}
+
+		if hc := NewGet(server.URL).SetCert(invalid).GenerateRequest(); !errors.Is(hc.Err, &GenerateCertErr) {
+			t.Fatalf("期望GenerateCertErr，实际：%v", hc.Err)
+		}
+	})
+}
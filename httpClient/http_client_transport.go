@@ -0,0 +1,72 @@
+package httpClient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// transportKey Transport缓存的key：证书与分阶段超时相同的请求共享同一个Transport
+type transportKey struct {
+	cert          [sha256.Size]byte
+	dialTimeout   time.Duration
+	tlsTimeout    time.Duration
+	headerTimeout time.Duration
+}
+
+var (
+	transportLock  sync.Mutex
+	transportCache = map[transportKey]*http.Transport{}
+)
+
+// getTransport 获取证书与分阶段超时对应的Transport：未设置时返回nil（使用默认Transport）
+//
+// 相同配置共享Transport以复用链接，避免每个请求创建独立的链接池
+func getTransport(cert []byte, dialTimeout, tlsTimeout, headerTimeout time.Duration) (*http.Transport, error) {
+	if len(cert) == 0 && dialTimeout <= 0 && tlsTimeout <= 0 && headerTimeout <= 0 {
+		return nil, nil
+	}
+
+	key := transportKey{dialTimeout: max(dialTimeout, 0), tlsTimeout: max(tlsTimeout, 0), headerTimeout: max(headerTimeout, 0)}
+	if len(cert) > 0 {
+		key.cert = sha256.Sum256(cert)
+	}
+
+	transportLock.Lock()
+	defer transportLock.Unlock()
+
+	if transport, exists := transportCache[key]; exists {
+		return transport, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	// 创建一个新的证书池，并将证书添加到池中
+	if len(cert) > 0 {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(cert) {
+			return nil, GenerateCertErr.Panic()
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: certPool}
+	}
+
+	if key.dialTimeout > 0 {
+		transport.DialContext = (&net.Dialer{Timeout: key.dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	}
+
+	if key.tlsTimeout > 0 {
+		transport.TLSHandshakeTimeout = key.tlsTimeout
+	}
+
+	if key.headerTimeout > 0 {
+		transport.ResponseHeaderTimeout = key.headerTimeout
+	}
+
+	transportCache[key] = transport
+
+	return transport, nil
+}
@@ -0,0 +1,86 @@
+package httpLimiter
+
+import (
+	"sync"
+	"time"
+)
+
+// ConcurrencyLimiter 并发限流器：按key限制同时进行中的请求数，并发安全
+type ConcurrencyLimiter struct {
+	lock       sync.Mutex
+	max        uint
+	inFlight   map[string]uint
+	retryAfter time.Duration
+}
+
+var ConcurrencyLimiterApp ConcurrencyLimiter
+
+// New 实例化：并发限流器；每个key最多max个进行中的请求
+func (*ConcurrencyLimiter) New(max uint) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{max: max, inFlight: make(map[string]uint), retryAfter: time.Second}
+}
+
+// SetRetryAfter 设置未通过时建议的等待时间
+func (my *ConcurrencyLimiter) SetRetryAfter(retryAfter time.Duration) *ConcurrencyLimiter {
+	my.retryAfter = retryAfter
+
+	return my
+}
+
+// Acquire 占用1个并发配额：通过时需调用release归还，release可重复调用
+func (my *ConcurrencyLimiter) Acquire(key string) (*LimitResult, func()) {
+	return my.AcquireN(key, 1)
+}
+
+// AcquireN 占用n个并发配额：通过时需调用release归还，release可重复调用；未通过时release为空操作
+func (my *ConcurrencyLimiter) AcquireN(key string, n uint) (*LimitResult, func()) {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	now := time.Now()
+	current := my.inFlight[key]
+
+	if current+n > my.max {
+		result := &LimitResult{Limit: my.max, Remaining: my.max - current, ResetAt: now}
+		if n <= my.max {
+			result.RetryAfter = my.retryAfter
+		}
+		return result, func() {}
+	}
+
+	my.inFlight[key] = current + n
+
+	var once sync.Once
+
+	return &LimitResult{Allowed: true, Limit: my.max, Remaining: my.max - current - n, ResetAt: now}, func() {
+		once.Do(func() { my.release(key, n) })
+	}
+}
+
+// InFlight 获取key当前进行中的请求数
+func (my *ConcurrencyLimiter) InFlight(key string) uint {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	return my.inFlight[key]
+}
+
+// Len 当前有进行中请求的key数量
+func (my *ConcurrencyLimiter) Len() int {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	return len(my.inFlight)
+}
+
+// release 归还并发配额：归零的key会被删除
+func (my *ConcurrencyLimiter) release(key string, n uint) {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	if current := my.inFlight[key]; current > n {
+		my.inFlight[key] = current - n
+	} else {
+		delete(my.inFlight, key)
+	}
+}
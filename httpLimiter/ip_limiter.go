@@ -1,6 +1,7 @@
 package httpLimiter
 
 import (
+	"sync"
 	"time"
 )
 
@@ -13,8 +14,19 @@ type (
 		visitTimes uint16
 	}
 
-	// IpLimiter ip限流器
-	IpLimiter struct{ visitMap map[string]*Visit }
+	// IpLimiter ip限流器：并发安全，空闲超过窗口期的ip会被清理
+	IpLimiter struct {
+		lock      sync.Mutex
+		visitMap  map[string]*Visit
+		lastSweep time.Time
+	}
+
+	// ipLimiterAdapter IpLimiter适配Limiter接口：key为客户端ip
+	ipLimiterAdapter struct {
+		ipLimiter     *IpLimiter
+		t             time.Duration
+		maxVisitTimes uint16
+	}
 )
 
 var (
@@ -31,14 +43,21 @@ func (*IpLimiter) New() *IpLimiter { return NewIpLimiter() }
 // NewIpLimiter 实例化：Ip 限流
 //
 //go:fix 推荐使用New方法
-func NewIpLimiter() *IpLimiter { return &IpLimiter{visitMap: make(map[string]*Visit)} }
+func NewIpLimiter() *IpLimiter {
+	return &IpLimiter{visitMap: make(map[string]*Visit), lastSweep: time.Now()}
+}
 
-// Affirm 检查限流
+// Affirm 检查限流：未通过时返回访问记录的副本
 func (my *IpLimiter) Affirm(ip string, t time.Duration, maxVisitTimes uint16) (*Visit, bool) {
 	if maxVisitTimes == 0 || t == 0 {
 		return nil, true
 	}
 
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.sweep(t)
+
 	v, ok := my.visitMap[ip]
 	if !ok {
 		my.visitMap[ip] = VisitApp.New()
@@ -50,7 +69,7 @@ func (my *IpLimiter) Affirm(ip string, t time.Duration, maxVisitTimes uint16) (*
 	} else {
 		v.visitTimes++
 		if v.visitTimes > maxVisitTimes {
-			return v, false
+			return &Visit{lastVisit: v.lastVisit, visitTimes: v.visitTimes}, false
 		}
 	}
 	v.lastVisit = time.Now()
@@ -58,6 +77,87 @@ func (my *IpLimiter) Affirm(ip string, t time.Duration, maxVisitTimes uint16) (*
 	return nil, true
 }
 
+// Limiter 适配Limiter接口：窗口期t内最多maxVisitTimes次访问，可用于Middleware、QuotaPolicy；未通过的访问不计数
+//
+// maxVisitTimes或t为0时不限流，与Affirm一致
+func (my *IpLimiter) Limiter(t time.Duration, maxVisitTimes uint16) Limiter {
+	return &ipLimiterAdapter{ipLimiter: my, t: t, maxVisitTimes: maxVisitTimes}
+}
+
+// affirmN 检查限流，消耗n次访问：距最后一次访问超过窗口期时重新计数
+func (my *IpLimiter) affirmN(ip string, t time.Duration, maxVisitTimes uint16, n uint) *LimitResult {
+	if maxVisitTimes == 0 || t == 0 {
+		return unlimited()
+	}
+
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.sweep(t)
+
+	var (
+		now    = time.Now()
+		limit  = uint(maxVisitTimes)
+		used   uint
+		result = &LimitResult{Limit: limit, ResetAt: now}
+	)
+
+	v, ok := my.visitMap[ip]
+	if ok && now.Sub(v.lastVisit) <= t {
+		used = uint(v.visitTimes)
+		result.ResetAt = v.lastVisit.Add(t)
+	}
+
+	if used+n > limit {
+		result.Remaining = limit - min(used, limit)
+		if n <= limit {
+			result.RetryAfter = result.ResetAt.Sub(now)
+		}
+		return result
+	}
+
+	if !ok {
+		v = &Visit{}
+		my.visitMap[ip] = v
+	}
+	v.visitTimes = uint16(used + n)
+	v.lastVisit = now
+
+	result.Allowed = true
+	result.Remaining = limit - used - n
+	result.ResetAt = now.Add(t)
+
+	return result
+}
+
+// Len 当前记录的ip数量
+func (my *IpLimiter) Len() int {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	return len(my.visitMap)
+}
+
+// sweep 清理空闲超过窗口期的ip：每个窗口期最多清理一次
+func (my *IpLimiter) sweep(t time.Duration) {
+	if time.Since(my.lastSweep) < t {
+		return
+	}
+
+	for ip, v := range my.visitMap {
+		if time.Since(v.lastVisit) > t {
+			delete(my.visitMap, ip)
+		}
+	}
+	my.lastSweep = time.Now()
+}
+
+func (my *ipLimiterAdapter) Affirm(key string) *LimitResult { return my.AffirmN(key, 1) }
+
+func (my *ipLimiterAdapter) AffirmN(key string, n uint) *LimitResult {
+	return my.ipLimiter.affirmN(key, my.t, my.maxVisitTimes, n)
+}
+
 // GetLastVisitor 获取最后访问时间
 func (r *Visit) GetLastVisitor() time.Time { return r.lastVisit }
 
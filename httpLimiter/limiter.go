@@ -0,0 +1,138 @@
+package httpLimiter
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+type (
+	// Limiter 限流器：按key限流，并发安全
+	Limiter interface {
+		// Affirm 检查是否通过限流，消耗1个配额
+		Affirm(key string) *LimitResult
+		// AffirmN 检查是否通过限流，消耗n个配额
+		AffirmN(key string, n uint) *LimitResult
+	}
+
+	// Refunder 可归还配额的限流器：多个规则依次消耗配额时，后续规则未通过则归还之前已消耗的配额
+	Refunder interface {
+		// RefundN 归还n个配额
+		RefundN(key string, n uint)
+	}
+
+	// LimitResult 限流结果
+	LimitResult struct {
+		Allowed    bool          // 是否通过
+		Limit      uint          // 配额上限
+		Remaining  uint          // 剩余配额
+		ResetAt    time.Time     // 配额完全恢复（或窗口重置）的时间
+		RetryAfter time.Duration // 未通过时建议的等待时间：0表示请求的配额超过上限，等待也无法通过
+	}
+
+	// keyStore 按key保存限流状态：空闲超过ttl的key在访问时惰性清理
+	keyStore[T any] struct {
+		lock      sync.Mutex
+		items     map[string]*keyItem[T]
+		ttl       time.Duration
+		lastSweep time.Time
+	}
+
+	keyItem[T any] struct {
+		val      T
+		lastSeen time.Time
+	}
+)
+
+var (
+	_ Limiter = (*TokenBucketLimiter)(nil)
+	_ Limiter = (*SlidingLogLimiter)(nil)
+	_ Limiter = (*SlidingWindowLimiter)(nil)
+	_ Limiter = (*RedisLimiter)(nil)
+	_ Limiter = (*ipLimiterAdapter)(nil)
+	_ Limiter = (*routeLimiterAdapter)(nil)
+
+	_ Refunder = (*TokenBucketLimiter)(nil)
+	_ Refunder = (*SlidingLogLimiter)(nil)
+	_ Refunder = (*SlidingWindowLimiter)(nil)
+	_ Refunder = (*RedisLimiter)(nil)
+)
+
+// checkLimit 检查配额上限和窗口期：为0时无法计算补充速率、重试等待时间
+func checkLimit(limit uint, period time.Duration) error {
+	if limit == 0 {
+		return fmt.Errorf("配额上限不能为0")
+	}
+	if period <= 0 {
+		return fmt.Errorf("窗口期必须大于0：%s", period)
+	}
+
+	return nil
+}
+
+// rejected 参数错误的限流器拒绝所有请求
+func rejected(limit uint) *LimitResult { return &LimitResult{Limit: limit, ResetAt: time.Now()} }
+
+// unlimited 未配置限流时的结果：配额上限和剩余配额为最大值
+func unlimited() *LimitResult {
+	return &LimitResult{Allowed: true, Limit: math.MaxUint, Remaining: math.MaxUint, ResetAt: time.Now()}
+}
+
+// refund 归还配额：限流器未实现Refunder时忽略
+func refund(limiter Limiter, key string, n uint) {
+	if refunder, ok := limiter.(Refunder); ok {
+		refunder.RefundN(key, n)
+	}
+}
+
+func newKeyStore[T any](ttl time.Duration) *keyStore[T] {
+	return &keyStore[T]{items: make(map[string]*keyItem[T]), ttl: ttl, lastSweep: time.Now()}
+}
+
+// do 在锁内读取或创建key对应的状态并执行fn
+func (my *keyStore[T]) do(key string, now time.Time, create func() T, fn func(val T) *LimitResult) *LimitResult {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.sweep(now)
+
+	item, exists := my.items[key]
+	if !exists {
+		item = &keyItem[T]{val: create()}
+		my.items[key] = item
+	}
+	item.lastSeen = now
+
+	return fn(item.val)
+}
+
+// sweep 清理空闲超过ttl的key：每个ttl周期最多清理一次
+func (my *keyStore[T]) sweep(now time.Time) {
+	if my.ttl <= 0 || now.Sub(my.lastSweep) < my.ttl {
+		return
+	}
+
+	for key, item := range my.items {
+		if now.Sub(item.lastSeen) >= my.ttl {
+			delete(my.items, key)
+		}
+	}
+	my.lastSweep = now
+}
+
+// setTtl 设置空闲key的过期时间
+func (my *keyStore[T]) setTtl(ttl time.Duration) {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.ttl = ttl
+}
+
+// len 当前保存的key数量
+func (my *keyStore[T]) len() int {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	return len(my.items)
+}
@@ -0,0 +1,171 @@
+package httpLimiter
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimiterInvalidArguments(t *testing.T) {
+	limiters := map[string]Limiter{
+		"tokenBucket limit=0":    TokenBucketLimiterApp.New(0, time.Second, 0),
+		"tokenBucket period=0":   TokenBucketLimiterApp.New(1, 0, 0),
+		"slidingLog limit=0":     SlidingLogLimiterApp.New(0, time.Second),
+		"slidingLog window=0":    SlidingLogLimiterApp.New(1, 0),
+		"slidingWindow limit=0":  SlidingWindowLimiterApp.New(0, time.Second),
+		"slidingWindow window=0": SlidingWindowLimiterApp.New(1, 0),
+	}
+
+	for name, limiter := range limiters {
+		var err error
+		switch l := limiter.(type) {
+		case *TokenBucketLimiter:
+			err = l.Err
+		case *SlidingLogLimiter:
+			err = l.Err
+		case *SlidingWindowLimiter:
+			err = l.Err
+		}
+		if err == nil {
+			t.Fatalf("%s：应设置Err", name)
+		}
+
+		result := limiter.Affirm("k")
+		if result.Allowed || result.RetryAfter != 0 || !result.ResetAt.After(time.Time{}) {
+			t.Fatalf("%s：应拒绝所有请求：%+v", name, result)
+		}
+	}
+}
+
+func TestTokenBucketLimiter(t *testing.T) {
+	limiter := TokenBucketLimiterApp.New(10, time.Second, 5)
+
+	for idx := range 5 {
+		if result := limiter.Affirm("k"); !result.Allowed || result.Remaining != uint(4-idx) || result.Limit != 5 {
+			t.Fatalf("桶容量内第%d次应通过：%+v", idx+1, result)
+		}
+	}
+
+	result := limiter.Affirm("k")
+	if result.Allowed || result.Remaining != 0 {
+		t.Fatalf("令牌耗尽应拒绝：%+v", result)
+	}
+	// 每100ms补充1个令牌
+	if result.RetryAfter <= 0 || result.RetryAfter > 100*time.Millisecond || isInvalidDuration(result.RetryAfter) {
+		t.Fatalf("重试等待时间错误：%s", result.RetryAfter)
+	}
+	if reset := time.Until(result.ResetAt); reset <= 400*time.Millisecond || reset > 500*time.Millisecond {
+		t.Fatalf("补满时间错误：%s", reset)
+	}
+
+	if result = limiter.AffirmN("other", 6); result.Allowed || result.RetryAfter != 0 {
+		t.Fatalf("超过桶容量的请求应拒绝且无法重试：%+v", result)
+	}
+	if result = limiter.AffirmN("other", 5); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("等于桶容量的请求应通过：%+v", result)
+	}
+
+	limiter.RefundN("other", 10)
+	if result = limiter.AffirmN("other", 5); !result.Allowed {
+		t.Fatalf("归还的令牌应可用：%+v", result)
+	}
+	if result = limiter.Affirm("other"); result.Allowed {
+		t.Fatalf("归还不应超过桶容量：%+v", result)
+	}
+}
+
+func TestSlidingLogLimiter(t *testing.T) {
+	limiter := SlidingLogLimiterApp.New(3, 100*time.Millisecond)
+
+	start := time.Now()
+	for idx := range 3 {
+		if result := limiter.Affirm("k"); !result.Allowed || result.Remaining != uint(2-idx) {
+			t.Fatalf("第%d次应通过：%+v", idx+1, result)
+		}
+	}
+
+	result := limiter.Affirm("k")
+	if result.Allowed || result.Remaining != 0 || result.RetryAfter <= 0 || result.RetryAfter > 100*time.Millisecond {
+		t.Fatalf("超过配额应拒绝：%+v", result)
+	}
+	if result = limiter.AffirmN("k", 4); result.Allowed || result.RetryAfter != 0 {
+		t.Fatalf("超过上限的请求应拒绝且无法重试：%+v", result)
+	}
+
+	limiter.RefundN("k", 1)
+	if result = limiter.Affirm("k"); !result.Allowed {
+		t.Fatalf("归还后应通过：%+v", result)
+	}
+
+	// 窗口期结束后配额完全恢复
+	time.Sleep(time.Until(start.Add(110 * time.Millisecond)))
+	if result = limiter.AffirmN("k", 3); !result.Allowed {
+		t.Fatalf("窗口期结束后应通过：%+v", result)
+	}
+}
+
+func TestSlidingWindowLimiter(t *testing.T) {
+	limiter := SlidingWindowLimiterApp.New(4, time.Hour)
+
+	for idx := range 4 {
+		if result := limiter.Affirm("k"); !result.Allowed || result.Remaining != uint(3-idx) {
+			t.Fatalf("第%d次应通过：%+v", idx+1, result)
+		}
+	}
+
+	result := limiter.Affirm("k")
+	if result.Allowed || result.Remaining != 0 || isInvalidDuration(result.RetryAfter) {
+		t.Fatalf("超过配额应拒绝：%+v", result)
+	}
+	// 当前窗口已满时，需要等到下一个窗口中当前窗口的权重衰减
+	if end := time.Until(time.Now().Truncate(time.Hour).Add(time.Hour)); result.RetryAfter < end || result.RetryAfter > end+time.Hour/4+time.Second {
+		t.Fatalf("重试等待时间错误：%s", result.RetryAfter)
+	}
+	if result = limiter.AffirmN("k", 5); result.Allowed || result.RetryAfter != 0 {
+		t.Fatalf("超过上限的请求应拒绝且无法重试：%+v", result)
+	}
+
+	limiter.RefundN("k", 2)
+	if result = limiter.AffirmN("k", 2); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("归还后应通过：%+v", result)
+	}
+	limiter.RefundN("k", 10)
+	if result = limiter.AffirmN("k", 4); !result.Allowed {
+		t.Fatalf("归还不应小于0：%+v", result)
+	}
+}
+
+func TestIpLimiterAdapter(t *testing.T) {
+	limiter := IpLimiterApp.New().Limiter(time.Minute, 2)
+
+	if !limiter.Affirm("1.1.1.1").Allowed || !limiter.Affirm("1.1.1.1").Allowed {
+		t.Fatal("配额内应通过")
+	}
+	result := limiter.Affirm("1.1.1.1")
+	if result.Allowed || result.Limit != 2 || result.RetryAfter <= 0 || result.RetryAfter > time.Minute {
+		t.Fatalf("超过配额应拒绝：%+v", result)
+	}
+	if !limiter.Affirm("2.2.2.2").Allowed {
+		t.Fatal("不同ip互不影响")
+	}
+
+	if result = IpLimiterApp.New().Limiter(0, 2).Affirm("1.1.1.1"); !result.Allowed || result.Remaining != math.MaxUint {
+		t.Fatalf("窗口期为0时不限流：%+v", result)
+	}
+}
+
+func TestRouteLimiterAdapter(t *testing.T) {
+	routeLimiter := (&RouteLimiter{RouteSetMap: new(sync.Map)}).Add("/api", time.Minute, 1)
+
+	limiter := routeLimiter.Limiter("/api")
+	if !limiter.Affirm("1.1.1.1").Allowed || limiter.Affirm("1.1.1.1").Allowed {
+		t.Fatal("路由配额应生效")
+	}
+	if !routeLimiter.Limiter("/other").Affirm("1.1.1.1").Allowed {
+		t.Fatal("未添加规则的路由不限流")
+	}
+}
+
+// isInvalidDuration 是否为无效的时长：NaN、Inf转换后为最小值
+func isInvalidDuration(d time.Duration) bool { return d < 0 || d == math.MinInt64 }
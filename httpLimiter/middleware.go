@@ -0,0 +1,329 @@
+package httpLimiter
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	// KeyFunc 限流key生成函数：如客户端ip、api key、用户id
+	KeyFunc func(request *http.Request) string
+
+	// LimitedHandler 限流未通过时的响应函数
+	LimitedHandler func(writer http.ResponseWriter, request *http.Request, result *LimitResult)
+
+	// Middleware 限流中间件：支持net/http和gin
+	Middleware struct {
+		Err            error
+		rules          []*middlewareRule
+		trustedProxies []*net.IPNet
+		keyFunc        KeyFunc
+		onLimited      LimitedHandler
+		policy         *QuotaPolicy
+	}
+
+	// middlewareRule 限流规则
+	middlewareRule struct {
+		method   string
+		pattern  string
+		segments []string
+		limiter  Limiter
+		keyFunc  KeyFunc
+	}
+)
+
+var MiddlewareApp Middleware
+
+// New 实例化：限流中间件；默认按客户端ip限流，不信任任何代理
+func (*Middleware) New() *Middleware {
+	middleware := &Middleware{onLimited: defaultLimitedHandler}
+	middleware.keyFunc = middleware.ClientIp
+
+	return middleware
+}
+
+// SetTrustedProxies 设置可信代理：支持ip和cidr；只有来自可信代理的请求才会读取X-Forwarded-For、X-Real-IP；格式错误时设置Err，Handler、Gin会panic
+func (my *Middleware) SetTrustedProxies(proxies ...string) *Middleware {
+	trustedProxies := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				my.Err = fmt.Errorf("可信代理格式错误：%s", proxy)
+				return my
+			}
+			if ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			my.Err = fmt.Errorf("可信代理格式错误：%w", err)
+			return my
+		}
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+	my.trustedProxies = trustedProxies
+
+	return my
+}
+
+// SetKeyFunc 设置默认限流key生成函数
+func (my *Middleware) SetKeyFunc(keyFunc KeyFunc) *Middleware {
+	my.keyFunc = keyFunc
+
+	return my
+}
+
+// SetOnLimited 设置限流未通过时的响应函数：响应头已设置完成，默认返回429
+func (my *Middleware) SetOnLimited(onLimited LimitedHandler) *Middleware {
+	my.onLimited = onLimited
+
+	return my
+}
+
+// SetPolicy 设置分层配额策略：在所有规则通过后检查，客户端标识使用默认限流key生成函数
+func (my *Middleware) SetPolicy(policy *QuotaPolicy) *Middleware {
+	my.policy = policy
+
+	return my
+}
+
+// Add 添加限流规则：method为空或*时匹配所有方法；pattern支持:name、{name}匹配单段路径，末尾*匹配剩余路径
+//
+// 请求会经过所有匹配的规则，不同规则的key互不影响
+func (my *Middleware) Add(method, pattern string, limiter Limiter) *Middleware {
+	return my.AddWithKeyFunc(method, pattern, limiter, nil)
+}
+
+// AddWithKeyFunc 添加限流规则并指定该规则的限流key生成函数：keyFunc为nil时使用默认函数
+func (my *Middleware) AddWithKeyFunc(method, pattern string, limiter Limiter, keyFunc KeyFunc) *Middleware {
+	if method == "*" {
+		method = ""
+	}
+
+	my.rules = append(my.rules, &middlewareRule{
+		method:   strings.ToUpper(method),
+		pattern:  pattern,
+		segments: splitPath(pattern),
+		limiter:  limiter,
+		keyFunc:  keyFunc,
+	})
+
+	return my
+}
+
+// Handler net/http中间件：Err不为空时panic，避免配置错误时静默按错误的客户端ip限流
+func (my *Middleware) Handler(next http.Handler) http.Handler {
+	if my.Err != nil {
+		panic(my.Err)
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		result, release := my.affirm(writer.Header(), request)
+		defer release()
+
+		if result != nil && !result.Allowed {
+			my.onLimited(writer, request, result)
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// Gin gin中间件：Err不为空时panic，避免配置错误时静默按错误的客户端ip限流
+func (my *Middleware) Gin() gin.HandlerFunc {
+	if my.Err != nil {
+		panic(my.Err)
+	}
+
+	return func(c *gin.Context) {
+		result, release := my.affirm(c.Writer.Header(), c.Request)
+		defer release()
+
+		if result != nil && !result.Allowed {
+			my.onLimited(c.Writer, c.Request, result)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ClientIp 获取客户端ip：RemoteAddr为可信代理时，从右向左取X-Forwarded-For中第一个非可信代理的地址，其次使用X-Real-IP
+func (my *Middleware) ClientIp(request *http.Request) string {
+	remoteIp, _, err := net.SplitHostPort(strings.TrimSpace(request.RemoteAddr))
+	if err != nil {
+		remoteIp = strings.TrimSpace(request.RemoteAddr)
+	}
+
+	if !my.isTrusted(remoteIp) {
+		return remoteIp
+	}
+
+	if forwardedFor := request.Header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+		ips := strings.Split(strings.Join(forwardedFor, ","), ",")
+		for idx := len(ips) - 1; idx >= 0; idx-- {
+			ip := strings.TrimSpace(ips[idx])
+			if net.ParseIP(ip) == nil {
+				break
+			}
+			if !my.isTrusted(ip) || idx == 0 {
+				return ip
+			}
+		}
+	}
+
+	if realIp := strings.TrimSpace(request.Header.Get("X-Real-IP")); net.ParseIP(realIp) != nil {
+		return realIp
+	}
+
+	return remoteIp
+}
+
+// isTrusted 是否为可信代理
+func (my *Middleware) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, ipNet := range my.trustedProxies {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// affirm 检查所有匹配的规则和配额策略并设置限流响应头：返回未通过的结果，全部通过时返回剩余配额最少的结果，没有匹配的规则时返回nil
+//
+// 任意规则或配额策略未通过时，归还之前规则已消耗的配额；release用于归还配额策略占用的并发配额，需在请求处理完成后调用
+func (my *Middleware) affirm(header http.Header, request *http.Request) (*LimitResult, func()) {
+	var (
+		current *LimitResult
+		charged []func()
+	)
+
+	release := func() {}
+	segments := splitPath(request.URL.Path)
+	for _, rule := range my.rules {
+		if !rule.match(request.Method, segments) {
+			continue
+		}
+
+		keyFunc := rule.keyFunc
+		if keyFunc == nil {
+			keyFunc = my.keyFunc
+		}
+
+		key := rule.method + " " + rule.pattern + " " + keyFunc(request)
+		result := rule.limiter.Affirm(key)
+		if !result.Allowed {
+			current = result
+			break
+		}
+		charged = append(charged, func() { refund(rule.limiter, key, 1) })
+		current = lowerRemaining(current, result)
+	}
+
+	if my.policy != nil && (current == nil || current.Allowed) {
+		var result *LimitResult
+		if result, release = my.policy.Affirm(request, my.keyFunc(request)); result != nil {
+			if result.Allowed {
+				current = lowerRemaining(current, result)
+			} else {
+				current = result
+			}
+		}
+	}
+
+	if current != nil && !current.Allowed {
+		for _, fn := range charged {
+			fn()
+		}
+	}
+
+	if current != nil {
+		setRateLimitHeaders(header, current)
+	}
+
+	return current, release
+}
+
+// match 是否匹配请求方法和路径
+func (my *middlewareRule) match(method string, segments []string) bool {
+	if my.method != "" && my.method != method {
+		return false
+	}
+
+	return matchSegments(my.segments, segments)
+}
+
+// matchSegments 路径是否匹配：:name、{name}匹配单段路径，末尾*匹配剩余路径
+func matchSegments(patterns, segments []string) bool {
+	for idx, pattern := range patterns {
+		if pattern == "*" && idx == len(patterns)-1 {
+			return true
+		}
+		if idx >= len(segments) {
+			return false
+		}
+		if strings.HasPrefix(pattern, ":") || (strings.HasPrefix(pattern, "{") && strings.HasSuffix(pattern, "}")) {
+			continue
+		}
+		if pattern != segments[idx] {
+			return false
+		}
+	}
+
+	return len(patterns) == len(segments)
+}
+
+// splitPath 拆分路径
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+
+	return strings.Split(path, "/")
+}
+
+// setRateLimitHeaders 设置RateLimit-Limit、RateLimit-Remaining、RateLimit-Reset，未通过时设置Retry-After
+func setRateLimitHeaders(header http.Header, result *LimitResult) {
+	header.Set("RateLimit-Limit", strconv.FormatUint(uint64(result.Limit), 10))
+	header.Set("RateLimit-Remaining", strconv.FormatUint(uint64(result.Remaining), 10))
+	header.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(time.Until(result.ResetAt)), 10))
+
+	if !result.Allowed && result.RetryAfter > 0 {
+		header.Set("Retry-After", strconv.FormatInt(max(1, ceilSeconds(result.RetryAfter)), 10))
+	}
+}
+
+// ceilSeconds 向上取整为秒
+func ceilSeconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+
+	return int64(math.Ceil(d.Seconds()))
+}
+
+// defaultLimitedHandler 默认限流响应：429
+func defaultLimitedHandler(writer http.ResponseWriter, _ *http.Request, _ *LimitResult) {
+	http.Error(writer, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
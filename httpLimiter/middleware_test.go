@@ -0,0 +1,58 @@
+package httpLimiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewareTrustedProxies(t *testing.T) {
+	t.Run("可信代理格式错误", func(t *testing.T) {
+		for _, proxy := range []string{"10.0.0.300", "10.0.0.0/33", "proxy.local"} {
+			middleware := MiddlewareApp.New().SetTrustedProxies("10.0.0.1", proxy)
+			if middleware.Err == nil {
+				t.Fatalf("%s：应设置Err", proxy)
+			}
+
+			for name, fn := range map[string]func(){
+				"Handler": func() { middleware.Handler(http.NotFoundHandler()) },
+				"Gin":     func() { middleware.Gin() },
+			} {
+				func() {
+					defer func() {
+						if recover() == nil {
+							t.Fatalf("%s %s：应panic", proxy, name)
+						}
+					}()
+					fn()
+				}()
+			}
+		}
+	})
+
+	t.Run("只信任可信代理转发的地址", func(t *testing.T) {
+		middleware := MiddlewareApp.New().SetTrustedProxies("10.0.0.1", "192.168.0.0/16")
+		if middleware.Err != nil {
+			t.Fatalf("设置可信代理失败：%v", middleware.Err)
+		}
+
+		cases := []struct {
+			remoteAddr, forwardedFor, want string
+		}{
+			{"1.1.1.1:80", "2.2.2.2", "1.1.1.1"},
+			{"10.0.0.1:80", "2.2.2.2", "2.2.2.2"},
+			{"10.0.0.1:80", "3.3.3.3, 2.2.2.2, 192.168.1.1", "2.2.2.2"},
+			{"10.0.0.1:80", "", "10.0.0.1"},
+		}
+		for _, c := range cases {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.RemoteAddr = c.remoteAddr
+			if c.forwardedFor != "" {
+				request.Header.Set("X-Forwarded-For", c.forwardedFor)
+			}
+			if got := middleware.ClientIp(request); got != c.want {
+				t.Fatalf("%s %s：期望%s，实际%s", c.remoteAddr, c.forwardedFor, c.want, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,367 @@
+package httpLimiter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jericho-yu/aid/honestMan"
+)
+
+type (
+	// QuotaScope 配额范围
+	QuotaScope string
+
+	// QuotaAlgorithm 配额算法
+	QuotaAlgorithm string
+
+	// CostFunc 请求权重函数：返回0时使用规则中配置的权重
+	CostFunc func(request *http.Request) uint
+
+	// QuotaSetting 配额配置
+	QuotaSetting struct {
+		Rules []*QuotaRule `yaml:"rules"`
+	}
+
+	// QuotaRule 配额规则
+	QuotaRule struct {
+		Name      string         `yaml:"name"`      // 规则名称：不同规则的key互不影响，为空时由范围、方法、路径生成
+		Scope     QuotaScope     `yaml:"scope"`     // 范围：global、route、tenant
+		Method    string         `yaml:"method"`    // 请求方法：为空或*时匹配所有方法
+		Pattern   string         `yaml:"pattern"`   // 路径：同Middleware.Add；global范围忽略，tenant范围为空时匹配所有路径
+		Tenants   []string       `yaml:"tenants"`   // 租户：tenant范围下只对这些租户生效，为空时对所有租户生效；租户匹配到指定租户的规则时，不再检查未指定租户的tenant规则
+		PerClient bool           `yaml:"perClient"` // 是否按客户端分别计算配额
+		Algorithm QuotaAlgorithm `yaml:"algorithm"` // 算法：slidingWindow（默认）、slidingLog、tokenBucket、concurrency
+		Limit     uint           `yaml:"limit"`     // 窗口期内配额上限；concurrency为最大并发数
+		Window    string         `yaml:"window"`    // 窗口期：如1s、1m；concurrency忽略
+		Burst     uint           `yaml:"burst"`     // 令牌桶容量：为0时等于limit
+		Cost      uint           `yaml:"cost"`      // 匹配该规则的请求的权重：取所有匹配规则中的最大值，默认1
+	}
+
+	// QuotaPolicy 分层配额策略：全局、路由、租户规则同时生效，支持请求权重和并发限制
+	QuotaPolicy struct {
+		Err        error
+		rules      []*quotaRule
+		tenantFunc KeyFunc
+		costFunc   CostFunc
+	}
+
+	// quotaRule 配额规则
+	quotaRule struct {
+		*QuotaRule
+		method      string
+		segments    []string
+		tenants     map[string]struct{}
+		limiter     Limiter
+		concurrency *ConcurrencyLimiter
+	}
+)
+
+const (
+	QuotaScopeGlobal QuotaScope = "global" // 全局
+	QuotaScopeRoute  QuotaScope = "route"  // 路由
+	QuotaScopeTenant QuotaScope = "tenant" // 租户
+
+	QuotaAlgorithmSlidingWindow QuotaAlgorithm = "slidingWindow" // 滑动窗口计数
+	QuotaAlgorithmSlidingLog    QuotaAlgorithm = "slidingLog"    // 滑动日志
+	QuotaAlgorithmTokenBucket   QuotaAlgorithm = "tokenBucket"   // 令牌桶
+	QuotaAlgorithmConcurrency   QuotaAlgorithm = "concurrency"   // 并发数
+)
+
+var (
+	QuotaSettingApp QuotaSetting
+	QuotaPolicyApp  QuotaPolicy
+)
+
+// New 初始化：配额配置
+func (*QuotaSetting) New(path string) *QuotaSetting {
+	var quotaSetting *QuotaSetting = &QuotaSetting{}
+
+	if err := honestMan.HonestManApp.New(path).LoadYaml(quotaSetting); err != nil {
+		return nil
+	}
+
+	return quotaSetting
+}
+
+// ExampleYaml 示例配置文件
+func (*QuotaSetting) ExampleYaml() string {
+	return `rules:
+  - scope: "global"
+    algorithm: "slidingWindow"
+    limit: 10000
+    window: "1s"
+  - scope: "global"
+    algorithm: "concurrency"
+    limit: 500
+  - scope: "route"
+    method: "GET"
+    pattern: "/api/export/*"
+    perClient: true
+    algorithm: "tokenBucket"
+    limit: 100
+    window: "1m"
+    burst: 20
+    cost: 10
+  - scope: "tenant"
+    algorithm: "slidingWindow"
+    limit: 1000
+    window: "1m"
+  - scope: "tenant"
+    tenants: ["vip"]
+    algorithm: "slidingWindow"
+    limit: 10000
+    window: "1m"`
+}
+
+// New 实例化：分层配额策略；setting为nil时创建空策略
+func (*QuotaPolicy) New(setting *QuotaSetting) *QuotaPolicy {
+	policy := &QuotaPolicy{}
+
+	if setting != nil {
+		for _, rule := range setting.Rules {
+			if policy.AddRule(rule); policy.Err != nil {
+				break
+			}
+		}
+	}
+
+	return policy
+}
+
+// SetTenantFunc 设置租户获取函数：如从api key、用户id获取租户；返回空时跳过tenant范围的规则
+func (my *QuotaPolicy) SetTenantFunc(tenantFunc KeyFunc) *QuotaPolicy {
+	my.tenantFunc = tenantFunc
+
+	return my
+}
+
+// SetCostFunc 设置请求权重函数
+func (my *QuotaPolicy) SetCostFunc(costFunc CostFunc) *QuotaPolicy {
+	my.costFunc = costFunc
+
+	return my
+}
+
+// AddRule 添加规则：按规则中的算法创建限流器
+func (my *QuotaPolicy) AddRule(rule *QuotaRule) *QuotaPolicy {
+	if rule.Limit == 0 {
+		my.Err = fmt.Errorf("配额规则%s：limit不能为0", rule.Name)
+		return my
+	}
+
+	if rule.Algorithm == QuotaAlgorithmConcurrency {
+		return my.add(rule, nil, ConcurrencyLimiterApp.New(rule.Limit))
+	}
+
+	window, err := time.ParseDuration(rule.Window)
+	if err != nil || window <= 0 {
+		my.Err = fmt.Errorf("配额规则%s：窗口期错误：%s", rule.Name, rule.Window)
+		return my
+	}
+
+	switch rule.Algorithm {
+	case "", QuotaAlgorithmSlidingWindow:
+		return my.add(rule, SlidingWindowLimiterApp.New(rule.Limit, window), nil)
+	case QuotaAlgorithmSlidingLog:
+		return my.add(rule, SlidingLogLimiterApp.New(rule.Limit, window), nil)
+	case QuotaAlgorithmTokenBucket:
+		burst := rule.Burst
+		if burst == 0 {
+			burst = rule.Limit
+		}
+		return my.add(rule, TokenBucketLimiterApp.New(rule.Limit, window, burst), nil)
+	default:
+		my.Err = fmt.Errorf("配额规则%s：不支持的算法：%s", rule.Name, rule.Algorithm)
+		return my
+	}
+}
+
+// AddLimiter 添加规则并指定限流器：如RedisLimiter；规则中的算法、配额、窗口期会被忽略；限流器实现Refunder时，其他规则未通过会归还该规则已消耗的配额
+func (my *QuotaPolicy) AddLimiter(rule *QuotaRule, limiter Limiter) *QuotaPolicy {
+	return my.add(rule, limiter, nil)
+}
+
+// AddConcurrency 添加规则并指定并发限流器
+func (my *QuotaPolicy) AddConcurrency(rule *QuotaRule, concurrency *ConcurrencyLimiter) *QuotaPolicy {
+	return my.add(rule, nil, concurrency)
+}
+
+// add 添加规则
+func (my *QuotaPolicy) add(rule *QuotaRule, limiter Limiter, concurrency *ConcurrencyLimiter) *QuotaPolicy {
+	switch rule.Scope {
+	case QuotaScopeGlobal, QuotaScopeRoute, QuotaScopeTenant:
+	default:
+		my.Err = fmt.Errorf("配额规则%s：不支持的范围：%s", rule.Name, rule.Scope)
+		return my
+	}
+
+	if rule.Scope == QuotaScopeRoute && rule.Pattern == "" {
+		my.Err = fmt.Errorf("配额规则%s：route范围的规则必须设置pattern", rule.Name)
+		return my
+	}
+
+	method := strings.ToUpper(rule.Method)
+	if method == "*" {
+		method = ""
+	}
+
+	r := &quotaRule{QuotaRule: rule, method: method, limiter: limiter, concurrency: concurrency}
+
+	if rule.Scope != QuotaScopeGlobal && rule.Pattern != "" {
+		r.segments = splitPath(rule.Pattern)
+	}
+
+	if len(rule.Tenants) > 0 {
+		r.tenants = make(map[string]struct{}, len(rule.Tenants))
+		for _, tenant := range rule.Tenants {
+			r.tenants[tenant] = struct{}{}
+		}
+	}
+
+	if rule.Name == "" {
+		rule.Name = fmt.Sprintf("%s %s %s %s", rule.Scope, method, rule.Pattern, strings.Join(rule.Tenants, ","))
+	}
+
+	my.rules = append(my.rules, r)
+
+	return my
+}
+
+// Affirm 检查所有匹配的规则：client为客户端标识（如ip），用于perClient规则
+//
+// 先占用并发配额再依次消耗速率配额，任意规则未通过时归还已占用的并发配额和之前规则已消耗的速率配额；通过时需调用release归还并发配额
+// 返回未通过的结果，全部通过时返回剩余配额最少的结果，没有匹配的规则时返回nil
+func (my *QuotaPolicy) Affirm(request *http.Request, client string) (*LimitResult, func()) {
+	var (
+		tenant   string
+		releases []func()
+		current  *LimitResult
+		matched  = make([]*quotaRule, 0, len(my.rules))
+	)
+
+	if my.tenantFunc != nil {
+		tenant = my.tenantFunc(request)
+	}
+
+	segments := splitPath(request.URL.Path)
+	explicit := false
+	for _, rule := range my.rules {
+		if rule.match(request.Method, segments, tenant) {
+			matched = append(matched, rule)
+			explicit = explicit || rule.tenants != nil
+		}
+	}
+
+	// 指定租户的规则覆盖未指定租户的tenant规则
+	cost := uint(0)
+	filtered := matched[:0]
+	for _, rule := range matched {
+		if explicit && rule.Scope == QuotaScopeTenant && rule.tenants == nil {
+			continue
+		}
+		filtered = append(filtered, rule)
+		cost = max(cost, rule.Cost)
+	}
+	matched = filtered
+
+	if len(matched) == 0 {
+		return nil, func() {}
+	}
+
+	if my.costFunc != nil {
+		if c := my.costFunc(request); c > 0 {
+			cost = c
+		}
+	}
+	cost = max(cost, 1)
+
+	release := func() {
+		for _, fn := range releases {
+			fn()
+		}
+	}
+
+	// 先检查并发配额，未通过时不会消耗速率配额
+	for _, rule := range matched {
+		if rule.concurrency == nil {
+			continue
+		}
+
+		result, fn := rule.concurrency.Acquire(rule.key(tenant, client))
+		if !result.Allowed {
+			release()
+			return result, func() {}
+		}
+		releases = append(releases, fn)
+		current = lowerRemaining(current, result)
+	}
+
+	var charged []func()
+	for _, rule := range matched {
+		if rule.limiter == nil {
+			continue
+		}
+
+		key := rule.key(tenant, client)
+		result := rule.limiter.AffirmN(key, cost)
+		if !result.Allowed {
+			for _, fn := range charged {
+				fn()
+			}
+			release()
+			return result, func() {}
+		}
+		charged = append(charged, func() { refund(rule.limiter, key, cost) })
+		current = lowerRemaining(current, result)
+	}
+
+	return current, release
+}
+
+// match 是否匹配请求
+func (my *quotaRule) match(method string, segments []string, tenant string) bool {
+	if my.method != "" && my.method != method {
+		return false
+	}
+
+	if my.Scope == QuotaScopeTenant {
+		if tenant == "" {
+			return false
+		}
+		if my.tenants != nil {
+			if _, exists := my.tenants[tenant]; !exists {
+				return false
+			}
+		}
+	}
+
+	if my.segments == nil {
+		return true
+	}
+
+	return matchSegments(my.segments, segments)
+}
+
+// key 生成限流key
+func (my *quotaRule) key(tenant, client string) string {
+	key := my.Name
+	if my.Scope == QuotaScopeTenant {
+		key += "|" + tenant
+	}
+	if my.PerClient {
+		key += "|" + client
+	}
+
+	return key
+}
+
+// lowerRemaining 返回剩余配额较少的结果
+func lowerRemaining(current, result *LimitResult) *LimitResult {
+	if current == nil || result.Remaining < current.Remaining {
+		return result
+	}
+
+	return current
+}
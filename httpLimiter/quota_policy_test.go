@@ -0,0 +1,110 @@
+package httpLimiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTenantRequest 创建带租户请求头的请求
+func newTenantRequest(path, tenant string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	request.Header.Set("X-Tenant", tenant)
+
+	return request
+}
+
+func tenantFunc(request *http.Request) string { return request.Header.Get("X-Tenant") }
+
+func TestQuotaPolicyRefund(t *testing.T) {
+	for _, algorithm := range []QuotaAlgorithm{QuotaAlgorithmSlidingWindow, QuotaAlgorithmSlidingLog, QuotaAlgorithmTokenBucket} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			policy := QuotaPolicyApp.New(&QuotaSetting{Rules: []*QuotaRule{
+				{Name: "global", Scope: QuotaScopeGlobal, Algorithm: algorithm, Limit: 3, Window: "1m"},
+				{Name: "tenant", Scope: QuotaScopeTenant, Algorithm: algorithm, Limit: 1, Window: "1m"},
+			}}).SetTenantFunc(tenantFunc)
+			if policy.Err != nil {
+				t.Fatalf("创建策略失败：%v", policy.Err)
+			}
+
+			if result, _ := policy.Affirm(newTenantRequest("/", "a"), ""); !result.Allowed {
+				t.Fatalf("第一次请求应通过：%+v", result)
+			}
+
+			// 租户规则拒绝的请求不消耗全局配额
+			for range 5 {
+				if result, _ := policy.Affirm(newTenantRequest("/", "a"), ""); result.Allowed {
+					t.Fatalf("超过租户配额应拒绝：%+v", result)
+				}
+			}
+
+			for _, tenant := range []string{"b", "c"} {
+				if result, _ := policy.Affirm(newTenantRequest("/", tenant), ""); !result.Allowed {
+					t.Fatalf("租户%s应通过：%+v", tenant, result)
+				}
+			}
+			if result, _ := policy.Affirm(newTenantRequest("/", "d"), ""); result.Allowed {
+				t.Fatalf("超过全局配额应拒绝：%+v", result)
+			}
+		})
+	}
+}
+
+func TestQuotaPolicyConcurrency(t *testing.T) {
+	policy := QuotaPolicyApp.New(&QuotaSetting{Rules: []*QuotaRule{
+		{Name: "concurrency", Scope: QuotaScopeGlobal, Algorithm: QuotaAlgorithmConcurrency, Limit: 1},
+		{Name: "global", Scope: QuotaScopeGlobal, Limit: 2, Window: "1m"},
+	}})
+
+	result, release := policy.Affirm(newTenantRequest("/", ""), "")
+	if !result.Allowed {
+		t.Fatalf("第一次请求应通过：%+v", result)
+	}
+
+	// 并发配额未通过时不消耗速率配额
+	if result, _ = policy.Affirm(newTenantRequest("/", ""), ""); result.Allowed {
+		t.Fatalf("超过并发配额应拒绝：%+v", result)
+	}
+	release()
+
+	if result, release = policy.Affirm(newTenantRequest("/", ""), ""); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("归还并发配额后应通过：%+v", result)
+	}
+	release()
+}
+
+func TestMiddlewareRefund(t *testing.T) {
+	route := SlidingWindowLimiterApp.New(2, time.Minute)
+	policy := QuotaPolicyApp.New(&QuotaSetting{Rules: []*QuotaRule{
+		{Name: "tenant", Scope: QuotaScopeTenant, Limit: 1, Window: "1m"},
+	}}).SetTenantFunc(tenantFunc)
+
+	handler := MiddlewareApp.New().Add(http.MethodGet, "/api", route).SetPolicy(policy).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(tenant string) int {
+		recorder := httptest.NewRecorder()
+		request := newTenantRequest("/api", tenant)
+		request.RemoteAddr = "10.0.0.1:1234"
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	if code := serve("a"); code != http.StatusOK {
+		t.Fatalf("第一次请求应通过：%d", code)
+	}
+
+	// 配额策略拒绝的请求不消耗路由规则的配额
+	for range 3 {
+		if code := serve("a"); code != http.StatusTooManyRequests {
+			t.Fatalf("超过租户配额应拒绝：%d", code)
+		}
+	}
+
+	if code := serve("b"); code != http.StatusOK {
+		t.Fatalf("其他租户应通过：%d", code)
+	}
+	if code := serve("c"); code != http.StatusTooManyRequests {
+		t.Fatalf("超过路由配额应拒绝：%d", code)
+	}
+}
@@ -0,0 +1,327 @@
+package httpLimiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/jericho-yu/aid/redisPool"
+	rds "github.com/redis/go-redis/v9"
+)
+
+type (
+	// RedisAlgorithm redis限流算法
+	RedisAlgorithm string
+
+	// RedisLimiter redis分布式限流器：多个实例共享配额；redis不可用时使用本地限流器兜底
+	RedisLimiter struct {
+		Err           error
+		algorithm     RedisAlgorithm
+		limit         uint
+		window        time.Duration
+		prefix        string
+		client        *rds.Client
+		timeout       time.Duration
+		cooldown      time.Duration
+		lastFailure   atomic.Int64
+		fallback      Limiter
+		onError       func(err error)
+		slidingScript *rds.Script
+		bucketScript  *rds.Script
+	}
+)
+
+const (
+	RedisAlgorithmSlidingWindow RedisAlgorithm = "slidingWindow" // 滑动窗口计数
+	RedisAlgorithmTokenBucket   RedisAlgorithm = "tokenBucket"   // 令牌桶
+)
+
+var (
+	RedisLimiterApp RedisLimiter
+
+	// slidingWindowScript 滑动窗口计数：使用redis服务器时间，窗口计数保存在KEYS[1]的hash中（字段为窗口序号），返回{是否通过, 估算已用配额, 距窗口结束毫秒数}
+	slidingWindowScript = rds.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local idx = math.floor(now / window)
+local counts = redis.call('HMGET', KEYS[1], tostring(idx), tostring(idx - 1))
+local current = tonumber(counts[1] or '0')
+local previous = tonumber(counts[2] or '0')
+local estimated = previous * (1 - (now - idx * window) / window) + current
+local allowed = 0
+if estimated + n <= limit then
+	redis.call('HINCRBY', KEYS[1], tostring(idx), n)
+	for _, field in ipairs(redis.call('HKEYS', KEYS[1])) do
+		if tonumber(field) < idx - 1 then
+			redis.call('HDEL', KEYS[1], field)
+		end
+	end
+	redis.call('PEXPIRE', KEYS[1], window * 2)
+	estimated = estimated + n
+	allowed = 1
+end
+return {allowed, math.ceil(estimated), (idx + 1) * window - now}
+`)
+
+	// tokenBucketScript 令牌桶：使用redis服务器时间，返回{是否通过, 剩余令牌, 重试等待毫秒数, 补满等待毫秒数}
+	tokenBucketScript = rds.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local retry = 0
+if tokens >= n then
+	tokens = tokens - n
+	allowed = 1
+elseif n <= burst then
+	retry = math.ceil((n - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + 1000)
+return {allowed, math.floor(tokens), retry, math.ceil((burst - tokens) / rate)}
+`)
+
+	// slidingWindowRefundScript 滑动窗口计数归还配额：优先从当前窗口扣减，不足时从上一个窗口扣减
+	slidingWindowRefundScript = rds.NewScript(`
+local window = tonumber(ARGV[1])
+local n = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local idx = math.floor(now / window)
+for _, field in ipairs({tostring(idx), tostring(idx - 1)}) do
+	local count = tonumber(redis.call('HGET', KEYS[1], field) or '0')
+	local refunded = math.min(n, count)
+	if refunded > 0 then
+		redis.call('HINCRBY', KEYS[1], field, -refunded)
+		n = n - refunded
+	end
+end
+return n
+`)
+
+	// tokenBucketRefundScript 令牌桶归还令牌：不超过桶容量
+	tokenBucketRefundScript = rds.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	return 0
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate + n)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + 1000)
+return 0
+`)
+)
+
+// New 实例化：redis分布式限流器；clientName为redisPool中的链接名，window内最多limit个配额（令牌桶时为桶容量）
+//
+// 默认兜底限流器为同参数的本地限流器，redis操作超时100ms，redis失败后1s内直接使用兜底限流器；链接不存在时设置Err，并始终使用兜底限流器
+//
+// limit为0或window不大于0时设置Err，并拒绝所有请求
+func (*RedisLimiter) New(pool *redisPool.RedisPool, clientName string, algorithm RedisAlgorithm, limit uint, window time.Duration) *RedisLimiter {
+	prefix, client := pool.GetClient(clientName)
+
+	limiter := &RedisLimiter{
+		algorithm:     algorithm,
+		limit:         limit,
+		window:        window,
+		prefix:        fmt.Sprintf("%s:limiter", prefix),
+		client:        client,
+		timeout:       100 * time.Millisecond,
+		cooldown:      time.Second,
+		slidingScript: slidingWindowScript,
+		bucketScript:  tokenBucketScript,
+	}
+
+	if limiter.Err = checkLimit(limit, window); limiter.Err == nil && client == nil {
+		limiter.Err = fmt.Errorf("没有找到redis链接：%s", clientName)
+	}
+
+	if algorithm == RedisAlgorithmTokenBucket {
+		limiter.fallback = TokenBucketLimiterApp.New(limit, window, limit)
+	} else {
+		limiter.fallback = SlidingWindowLimiterApp.New(limit, window)
+	}
+
+	return limiter
+}
+
+// SetFallback 设置redis不可用时的兜底限流器：nil表示redis不可用时直接放行
+func (my *RedisLimiter) SetFallback(fallback Limiter) *RedisLimiter {
+	my.fallback = fallback
+
+	return my
+}
+
+// SetTimeout 设置redis操作超时
+func (my *RedisLimiter) SetTimeout(timeout time.Duration) *RedisLimiter {
+	my.timeout = timeout
+
+	return my
+}
+
+// SetCooldown 设置redis失败后直接使用兜底限流器的时长
+func (my *RedisLimiter) SetCooldown(cooldown time.Duration) *RedisLimiter {
+	my.cooldown = cooldown
+
+	return my
+}
+
+// SetOnError 设置redis错误回调：用于记录日志、监控
+func (my *RedisLimiter) SetOnError(onError func(err error)) *RedisLimiter {
+	my.onError = onError
+
+	return my
+}
+
+// Affirm 检查是否通过限流
+func (my *RedisLimiter) Affirm(key string) *LimitResult { return my.AffirmN(key, 1) }
+
+// AffirmN 检查是否通过限流，消耗n个配额
+func (my *RedisLimiter) AffirmN(key string, n uint) *LimitResult {
+	if checkLimit(my.limit, my.window) != nil {
+		return rejected(my.limit)
+	}
+
+	if my.client == nil || time.Since(time.Unix(0, my.lastFailure.Load())) < my.cooldown {
+		return my.fallbackAffirm(key, n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), my.timeout)
+	defer cancel()
+
+	var (
+		err    error
+		result *LimitResult
+	)
+
+	if my.algorithm == RedisAlgorithmTokenBucket {
+		result, err = my.tokenBucket(ctx, key, n)
+	} else {
+		result, err = my.slidingWindow(ctx, key, n)
+	}
+	if err != nil {
+		my.lastFailure.Store(time.Now().UnixNano())
+		if my.onError != nil {
+			my.onError(err)
+		}
+		return my.fallbackAffirm(key, n)
+	}
+
+	return result
+}
+
+// RefundN 归还n个配额：redis不可用时归还兜底限流器的配额
+func (my *RedisLimiter) RefundN(key string, n uint) {
+	if checkLimit(my.limit, my.window) != nil {
+		return
+	}
+
+	if my.client == nil || time.Since(time.Unix(0, my.lastFailure.Load())) < my.cooldown {
+		if my.fallback != nil {
+			refund(my.fallback, key, n)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), my.timeout)
+	defer cancel()
+
+	var err error
+	if my.algorithm == RedisAlgorithmTokenBucket {
+		err = tokenBucketRefundScript.Run(ctx, my.client, []string{my.redisKey(key)}, float64(my.limit)/float64(my.window.Milliseconds()), my.limit, n).Err()
+	} else {
+		err = slidingWindowRefundScript.Run(ctx, my.client, []string{my.redisKey(key)}, my.window.Milliseconds(), n).Err()
+	}
+	if err != nil {
+		my.lastFailure.Store(time.Now().UnixNano())
+		if my.onError != nil {
+			my.onError(err)
+		}
+	}
+}
+
+// fallbackAffirm 使用兜底限流器
+func (my *RedisLimiter) fallbackAffirm(key string, n uint) *LimitResult {
+	if my.fallback == nil {
+		return &LimitResult{Allowed: true, Limit: my.limit, Remaining: my.limit}
+	}
+
+	return my.fallback.AffirmN(key, n)
+}
+
+// redisKey 生成redis key：使用hash tag保证同一个key的相关数据在同一个槽
+func (my *RedisLimiter) redisKey(key string) string {
+	return fmt.Sprintf("%s:%s:{%s}", my.prefix, my.algorithm, key)
+}
+
+// slidingWindow 滑动窗口计数
+func (my *RedisLimiter) slidingWindow(ctx context.Context, key string, n uint) (*LimitResult, error) {
+	values, err := my.slidingScript.Run(ctx, my.client, []string{my.redisKey(key)}, my.limit, my.window.Milliseconds(), n).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("限流脚本返回值错误：%v", values)
+	}
+
+	now := time.Now()
+	result := &LimitResult{
+		Allowed:   values[0] == 1,
+		Limit:     my.limit,
+		Remaining: uint(max(0, int64(my.limit)-values[1])),
+		ResetAt:   now.Add(time.Duration(values[2]) * time.Millisecond),
+	}
+	if !result.Allowed && n <= my.limit {
+		result.RetryAfter = time.Duration(values[2]) * time.Millisecond
+	}
+
+	return result, nil
+}
+
+// tokenBucket 令牌桶
+func (my *RedisLimiter) tokenBucket(ctx context.Context, key string, n uint) (*LimitResult, error) {
+	rate := float64(my.limit) / float64(my.window.Milliseconds()) // 每毫秒补充令牌数
+	if math.IsInf(rate, 0) || math.IsNaN(rate) || rate == 0 {
+		return nil, fmt.Errorf("令牌桶窗口期错误：%s", my.window)
+	}
+
+	values, err := my.bucketScript.Run(ctx, my.client, []string{my.redisKey(key)}, rate, my.limit, n).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("限流脚本返回值错误：%v", values)
+	}
+
+	now := time.Now()
+
+	return &LimitResult{
+		Allowed:    values[0] == 1,
+		Limit:      my.limit,
+		Remaining:  uint(max(0, values[1])),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAt:    now.Add(time.Duration(values[3]) * time.Millisecond),
+	}, nil
+}
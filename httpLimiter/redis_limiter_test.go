@@ -0,0 +1,129 @@
+package httpLimiter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jericho-yu/aid/redisPool"
+)
+
+var (
+	miniRedisOnce sync.Once
+	miniRedisIns  *miniredis.Miniredis
+)
+
+// newTestRedisPool 使用miniredis创建redis链接池：链接池为单例，所有测试共享同一个miniredis
+func newTestRedisPool(t *testing.T) (*redisPool.RedisPool, *miniredis.Miniredis) {
+	miniRedisOnce.Do(func() {
+		var err error
+		if miniRedisIns, err = miniredis.Run(); err != nil {
+			t.Fatalf("启动miniredis失败：%v", err)
+		}
+
+		filename := filepath.Join(os.TempDir(), fmt.Sprintf("http-limiter-redis-%d.yaml", os.Getpid()))
+		content := fmt.Sprintf("host: %s\nport: %s\nprefix: test\npool:\n  - key: limiter\n    prefix: limiter\n    dbNum: 0\n", miniRedisIns.Host(), miniRedisIns.Port())
+		if err = os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatalf("写入redis配置失败：%v", err)
+		}
+		defer func() { _ = os.Remove(filename) }()
+
+		redisPool.RedisPoolApp.Once(redisPool.RedisSettingApp.New(filename))
+	})
+	miniRedisIns.FlushAll()
+
+	return redisPool.RedisPoolApp.Once(nil), miniRedisIns
+}
+
+func TestRedisLimiterClient(t *testing.T) {
+	pool, _ := newTestRedisPool(t)
+
+	limiter := RedisLimiterApp.New(pool, "not-exists", RedisAlgorithmSlidingWindow, 1, time.Minute)
+	if limiter.Err == nil {
+		t.Fatal("链接不存在时应设置Err")
+	}
+	if !limiter.Affirm("k").Allowed || limiter.Affirm("k").Allowed {
+		t.Fatal("链接不存在时应使用兜底限流器")
+	}
+}
+
+func TestRedisLimiterSlidingWindow(t *testing.T) {
+	pool, mr := newTestRedisPool(t)
+
+	limiter := RedisLimiterApp.New(pool, "limiter", RedisAlgorithmSlidingWindow, 3, time.Minute).SetFallback(nil)
+	if limiter.Err != nil {
+		t.Fatalf("创建限流器失败：%v", limiter.Err)
+	}
+
+	for idx := range 3 {
+		if result := limiter.Affirm("k"); !result.Allowed || result.Remaining != uint(2-idx) {
+			t.Fatalf("第%d次应通过：%+v", idx+1, result)
+		}
+	}
+	if result := limiter.Affirm("k"); result.Allowed || result.RetryAfter <= 0 {
+		t.Fatalf("超过配额应拒绝：%+v", result)
+	}
+	if result := limiter.AffirmN("k", 4); result.Allowed || result.RetryAfter != 0 {
+		t.Fatalf("超过上限的请求应拒绝且无法重试：%+v", result)
+	}
+
+	// 所有数据都保存在通过KEYS传入的一个key中
+	if keys := mr.Keys(); !slices.Equal(keys, []string{"test:limiter:limiter:slidingWindow:{k}"}) {
+		t.Fatalf("redis key错误：%v", keys)
+	}
+}
+
+func TestRedisLimiterTokenBucket(t *testing.T) {
+	pool, mr := newTestRedisPool(t)
+
+	limiter := RedisLimiterApp.New(pool, "limiter", RedisAlgorithmTokenBucket, 2, time.Minute).SetFallback(nil)
+	if !limiter.Affirm("k").Allowed || !limiter.Affirm("k").Allowed {
+		t.Fatal("桶容量内应通过")
+	}
+	if result := limiter.Affirm("k"); result.Allowed || result.RetryAfter <= 0 || result.RetryAfter > 30*time.Second {
+		t.Fatalf("令牌耗尽应拒绝：%+v", result)
+	}
+
+	if keys := mr.Keys(); !slices.Equal(keys, []string{"test:limiter:limiter:tokenBucket:{k}"}) {
+		t.Fatalf("redis key错误：%v", keys)
+	}
+}
+
+func TestRedisLimiterFallback(t *testing.T) {
+	pool, mr := newTestRedisPool(t)
+
+	var errs []error
+	limiter := RedisLimiterApp.New(pool, "limiter", RedisAlgorithmSlidingWindow, 1, time.Minute).SetOnError(func(err error) { errs = append(errs, err) })
+
+	mr.SetError("模拟故障")
+	defer mr.SetError("")
+
+	if !limiter.Affirm("k").Allowed || limiter.Affirm("k").Allowed {
+		t.Fatal("redis不可用时应使用兜底限流器")
+	}
+	if len(errs) != 1 {
+		t.Fatalf("冷却期内不应重复访问redis：%v", errs)
+	}
+}
+
+func TestRedisLimiterRefund(t *testing.T) {
+	pool, _ := newTestRedisPool(t)
+
+	for _, algorithm := range []RedisAlgorithm{RedisAlgorithmSlidingWindow, RedisAlgorithmTokenBucket} {
+		limiter := RedisLimiterApp.New(pool, "limiter", algorithm, 2, time.Minute).SetFallback(nil)
+
+		if !limiter.AffirmN("k", 2).Allowed || limiter.Affirm("k").Allowed {
+			t.Fatalf("%s：配额应耗尽", algorithm)
+		}
+
+		limiter.RefundN("k", 1)
+		if !limiter.Affirm("k").Allowed || limiter.Affirm("k").Allowed {
+			t.Fatalf("%s：归还后应只能通过1次", algorithm)
+		}
+	}
+}
@@ -15,6 +15,12 @@ type (
 
 	// RouteLimiter 路由限流器
 	RouteLimiter struct{ RouteSetMap *sync.Map }
+
+	// routeLimiterAdapter RouteLimiter适配Limiter接口：key为客户端ip
+	routeLimiterAdapter struct {
+		routeLimiter *RouteLimiter
+		router       string
+	}
 )
 
 var (
@@ -55,3 +61,19 @@ func (my *RouteLimiter) Affirm(router, ip string) (*Visit, bool) {
 
 	return nil, true
 }
+
+// Limiter 适配Limiter接口：按router的限流规则检查，可用于Middleware、QuotaPolicy；router未添加规则时不限流
+func (my *RouteLimiter) Limiter(router string) Limiter {
+	return &routeLimiterAdapter{routeLimiter: my, router: router}
+}
+
+func (my *routeLimiterAdapter) Affirm(key string) *LimitResult { return my.AffirmN(key, 1) }
+
+func (my *routeLimiterAdapter) AffirmN(key string, n uint) *LimitResult {
+	if val, exist := my.routeLimiter.RouteSetMap.Load(my.router); exist {
+		v := val.(*visitor)
+		return v.ipLimiter.affirmN(key, v.t, v.maxVisitTimes, n)
+	}
+
+	return unlimited()
+}
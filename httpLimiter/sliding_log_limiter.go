@@ -0,0 +1,94 @@
+package httpLimiter
+
+import "time"
+
+type (
+	// SlidingLogLimiter 滑动日志限流器：记录窗口内每次请求的时间，精确但内存占用与limit成正比
+	SlidingLogLimiter struct {
+		Err    error
+		limit  uint
+		window time.Duration
+		store  *keyStore[*slidingLog]
+	}
+
+	slidingLog struct{ times []time.Time }
+)
+
+var SlidingLogLimiterApp SlidingLogLimiter
+
+// New 实例化：滑动日志限流器；空闲超过window的key会被清理；limit为0或window不大于0时设置Err，并拒绝所有请求
+func (*SlidingLogLimiter) New(limit uint, window time.Duration) *SlidingLogLimiter {
+	return &SlidingLogLimiter{Err: checkLimit(limit, window), limit: limit, window: window, store: newKeyStore[*slidingLog](window)}
+}
+
+// SetTtl 设置空闲key的过期时间
+func (my *SlidingLogLimiter) SetTtl(ttl time.Duration) *SlidingLogLimiter {
+	my.store.setTtl(ttl)
+
+	return my
+}
+
+// Len 当前保存的key数量
+func (my *SlidingLogLimiter) Len() int { return my.store.len() }
+
+// Affirm 检查是否通过限流
+func (my *SlidingLogLimiter) Affirm(key string) *LimitResult { return my.AffirmN(key, 1) }
+
+// AffirmN 检查是否通过限流，消耗n个配额
+func (my *SlidingLogLimiter) AffirmN(key string, n uint) *LimitResult {
+	if my.Err != nil {
+		return rejected(my.limit)
+	}
+
+	now := time.Now()
+
+	return my.store.do(
+		key,
+		now,
+		func() *slidingLog { return &slidingLog{} },
+		func(log *slidingLog) *LimitResult {
+			// 清理窗口外的记录
+			boundary := now.Add(-my.window)
+			expired := 0
+			for expired < len(log.times) && !log.times[expired].After(boundary) {
+				expired++
+			}
+			log.times = log.times[expired:]
+
+			result := &LimitResult{Limit: my.limit}
+			used := uint(len(log.times))
+			if used+n <= my.limit {
+				for range n {
+					log.times = append(log.times, now)
+				}
+				used += n
+				result.Allowed = true
+			} else if n <= my.limit {
+				// 等待足够多的记录移出窗口
+				result.RetryAfter = log.times[used+n-my.limit-1].Add(my.window).Sub(now)
+			}
+
+			result.Remaining = my.limit - used
+			result.ResetAt = now
+			if len(log.times) > 0 {
+				result.ResetAt = log.times[len(log.times)-1].Add(my.window)
+			}
+
+			return result
+		},
+	)
+}
+
+// RefundN 归还n个配额：删除最近的n条记录
+func (my *SlidingLogLimiter) RefundN(key string, n uint) {
+	my.store.do(
+		key,
+		time.Now(),
+		func() *slidingLog { return &slidingLog{} },
+		func(log *slidingLog) *LimitResult {
+			log.times = log.times[:len(log.times)-int(min(n, uint(len(log.times))))]
+
+			return nil
+		},
+	)
+}
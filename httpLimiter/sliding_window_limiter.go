@@ -0,0 +1,132 @@
+package httpLimiter
+
+import (
+	"math"
+	"time"
+)
+
+type (
+	// SlidingWindowLimiter 滑动窗口计数限流器：按上一个窗口的计数加权估算，内存占用固定
+	SlidingWindowLimiter struct {
+		Err    error
+		limit  uint
+		window time.Duration
+		store  *keyStore[*slidingWindow]
+	}
+
+	slidingWindow struct {
+		start    time.Time // 当前窗口开始时间
+		current  uint
+		previous uint
+	}
+)
+
+var SlidingWindowLimiterApp SlidingWindowLimiter
+
+// New 实例化：滑动窗口计数限流器；空闲超过2个window的key会被清理；limit为0或window不大于0时设置Err，并拒绝所有请求
+func (*SlidingWindowLimiter) New(limit uint, window time.Duration) *SlidingWindowLimiter {
+	return &SlidingWindowLimiter{Err: checkLimit(limit, window), limit: limit, window: window, store: newKeyStore[*slidingWindow](2 * window)}
+}
+
+// SetTtl 设置空闲key的过期时间
+func (my *SlidingWindowLimiter) SetTtl(ttl time.Duration) *SlidingWindowLimiter {
+	my.store.setTtl(ttl)
+
+	return my
+}
+
+// Len 当前保存的key数量
+func (my *SlidingWindowLimiter) Len() int { return my.store.len() }
+
+// Affirm 检查是否通过限流
+func (my *SlidingWindowLimiter) Affirm(key string) *LimitResult { return my.AffirmN(key, 1) }
+
+// AffirmN 检查是否通过限流，消耗n个配额
+func (my *SlidingWindowLimiter) AffirmN(key string, n uint) *LimitResult {
+	if my.Err != nil {
+		return rejected(my.limit)
+	}
+
+	now := time.Now()
+	start := now.Truncate(my.window)
+
+	return my.store.do(
+		key,
+		now,
+		func() *slidingWindow { return &slidingWindow{start: start} },
+		func(window *slidingWindow) *LimitResult {
+			my.roll(window, start)
+
+			weight := 1 - float64(now.Sub(start))/float64(my.window)
+			estimated := float64(window.previous)*weight + float64(window.current)
+
+			result := &LimitResult{Limit: my.limit, ResetAt: start.Add(my.window)}
+			if estimated+float64(n) <= float64(my.limit) {
+				window.current += n
+				estimated += float64(n)
+				result.Allowed = true
+			} else if n <= my.limit {
+				result.RetryAfter = my.retryAfter(window, now, start, n)
+			}
+
+			result.Remaining = uint(math.Max(0, float64(my.limit)-math.Ceil(estimated)))
+
+			return result
+		},
+	)
+}
+
+// RefundN 归还n个配额：优先从当前窗口扣减，消耗配额后窗口已滚动时从上一个窗口扣减
+func (my *SlidingWindowLimiter) RefundN(key string, n uint) {
+	if my.Err != nil {
+		return
+	}
+
+	now := time.Now()
+	start := now.Truncate(my.window)
+
+	my.store.do(
+		key,
+		now,
+		func() *slidingWindow { return &slidingWindow{start: start} },
+		func(window *slidingWindow) *LimitResult {
+			my.roll(window, start)
+
+			current := min(n, window.current)
+			window.current -= current
+			window.previous -= min(n-current, window.previous)
+
+			return nil
+		},
+	)
+}
+
+// roll 滚动窗口
+func (my *SlidingWindowLimiter) roll(window *slidingWindow, start time.Time) {
+	switch elapsed := start.Sub(window.start); {
+	case elapsed >= 2*my.window:
+		window.previous, window.current = 0, 0
+	case elapsed >= my.window:
+		window.previous, window.current = window.current, 0
+	}
+	window.start = start
+}
+
+// retryAfter 估算再次通过需要等待的时间：等待上一个窗口的权重衰减，不够时等到下一个窗口
+func (my *SlidingWindowLimiter) retryAfter(window *slidingWindow, now, start time.Time, n uint) time.Duration {
+	end := start.Add(my.window)
+
+	if window.previous > 0 && window.current+n <= my.limit {
+		// previous * (1 - elapsed/window) + current + n <= limit
+		weight := float64(my.limit-window.current-n) / float64(window.previous)
+		return start.Add(time.Duration((1 - weight) * float64(my.window))).Sub(now)
+	}
+
+	// 下一个窗口中当前窗口的计数作为previous
+	if window.current > 0 && n <= my.limit {
+		weight := float64(my.limit-n) / float64(window.current)
+		return end.Add(time.Duration((1 - weight) * float64(my.window))).Sub(now)
+	}
+
+	return end.Sub(now)
+}
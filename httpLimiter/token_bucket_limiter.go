@@ -0,0 +1,116 @@
+package httpLimiter
+
+import (
+	"math"
+	"time"
+)
+
+type (
+	// TokenBucketLimiter 令牌桶限流器：每period补充limit个令牌，桶容量为burst
+	TokenBucketLimiter struct {
+		Err    error
+		rate   float64 // 每秒补充令牌数
+		burst  uint
+		limit  uint
+		period time.Duration
+		store  *keyStore[*tokenBucket]
+	}
+
+	tokenBucket struct {
+		tokens float64
+		last   time.Time
+	}
+)
+
+var TokenBucketLimiterApp TokenBucketLimiter
+
+// New 实例化：令牌桶限流器；burst为0时等于limit；空闲key在令牌补满后清理
+//
+// limit为0或period不大于0时设置Err，并拒绝所有请求
+func (*TokenBucketLimiter) New(limit uint, period time.Duration, burst uint) *TokenBucketLimiter {
+	if burst == 0 {
+		burst = limit
+	}
+
+	limiter := &TokenBucketLimiter{burst: burst, limit: limit, period: period, store: newKeyStore[*tokenBucket](0)}
+	if limiter.Err = checkLimit(limit, period); limiter.Err != nil {
+		return limiter
+	}
+
+	limiter.rate = float64(limit) / period.Seconds()
+	limiter.store.setTtl(time.Duration(float64(burst) / limiter.rate * float64(time.Second)))
+
+	return limiter
+}
+
+// SetTtl 设置空闲key的过期时间
+func (my *TokenBucketLimiter) SetTtl(ttl time.Duration) *TokenBucketLimiter {
+	my.store.setTtl(ttl)
+
+	return my
+}
+
+// Len 当前保存的key数量
+func (my *TokenBucketLimiter) Len() int { return my.store.len() }
+
+// Affirm 检查是否通过限流
+func (my *TokenBucketLimiter) Affirm(key string) *LimitResult { return my.AffirmN(key, 1) }
+
+// AffirmN 检查是否通过限流，消耗n个令牌
+func (my *TokenBucketLimiter) AffirmN(key string, n uint) *LimitResult {
+	if my.Err != nil {
+		return rejected(my.burst)
+	}
+
+	now := time.Now()
+
+	return my.store.do(
+		key,
+		now,
+		func() *tokenBucket { return &tokenBucket{tokens: float64(my.burst), last: now} },
+		func(bucket *tokenBucket) *LimitResult {
+			// 补充令牌
+			bucket.tokens = math.Min(float64(my.burst), bucket.tokens+now.Sub(bucket.last).Seconds()*my.rate)
+			bucket.last = now
+
+			result := &LimitResult{Limit: my.burst}
+			if bucket.tokens >= float64(n) {
+				bucket.tokens -= float64(n)
+				result.Allowed = true
+			} else if n <= my.burst {
+				result.RetryAfter = my.duration(float64(n) - bucket.tokens)
+			}
+
+			result.Remaining = uint(math.Floor(bucket.tokens))
+			result.ResetAt = now.Add(my.duration(float64(my.burst) - bucket.tokens))
+
+			return result
+		},
+	)
+}
+
+// RefundN 归还n个令牌：不超过桶容量
+func (my *TokenBucketLimiter) RefundN(key string, n uint) {
+	if my.Err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	my.store.do(
+		key,
+		now,
+		func() *tokenBucket { return &tokenBucket{tokens: float64(my.burst), last: now} },
+		func(bucket *tokenBucket) *LimitResult {
+			bucket.tokens = math.Min(float64(my.burst), bucket.tokens+now.Sub(bucket.last).Seconds()*my.rate+float64(n))
+			bucket.last = now
+
+			return nil
+		},
+	)
+}
+
+// duration 补充指定数量令牌需要的时间
+func (my *TokenBucketLimiter) duration(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / my.rate * float64(time.Second)))
+}
@@ -0,0 +1,72 @@
+package lock
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jericho-yu/aid/array"
+	"github.com/jericho-yu/aid/myError"
+	"github.com/jericho-yu/aid/operation"
+)
+
+type (
+	LockBusyError     struct{ myError.MyError }
+	LeaseExpiredError struct{ myError.MyError }
+	RedisLockError    struct{ myError.MyError }
+)
+
+var (
+	LockBusyErr     LockBusyError
+	LeaseExpiredErr LeaseExpiredError
+	RedisLockErr    RedisLockError
+)
+
+func (*LockBusyError) New(msg string) myError.IMyError {
+	return &LockBusyError{myError.MyError{Msg: array.NewDestruction("锁被占用", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*LockBusyError) Wrap(err error) myError.IMyError {
+	return &LockBusyError{myError.MyError{Msg: fmt.Errorf("锁被占用"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*LockBusyError) Panic() myError.IMyError {
+	return &LockBusyError{myError.MyError{Msg: "锁被占用"}}
+}
+
+func (my *LockBusyError) Error() string { return my.Msg }
+
+func (my *LockBusyError) Is(target error) bool { return reflect.DeepEqual(target, &LockBusyErr) }
+
+func (*LeaseExpiredError) New(msg string) myError.IMyError {
+	return &LeaseExpiredError{myError.MyError{Msg: array.NewDestruction("锁租约已失效", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*LeaseExpiredError) Wrap(err error) myError.IMyError {
+	return &LeaseExpiredError{myError.MyError{Msg: fmt.Errorf("锁租约已失效"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*LeaseExpiredError) Panic() myError.IMyError {
+	return &LeaseExpiredError{myError.MyError{Msg: "锁租约已失效"}}
+}
+
+func (my *LeaseExpiredError) Error() string { return my.Msg }
+
+func (my *LeaseExpiredError) Is(target error) bool {
+	return reflect.DeepEqual(target, &LeaseExpiredErr)
+}
+
+func (*RedisLockError) New(msg string) myError.IMyError {
+	return &RedisLockError{myError.MyError{Msg: array.NewDestruction("分布式锁操作失败", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*RedisLockError) Wrap(err error) myError.IMyError {
+	return &RedisLockError{myError.MyError{Msg: fmt.Errorf("分布式锁操作失败"+operation.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*RedisLockError) Panic() myError.IMyError {
+	return &RedisLockError{myError.MyError{Msg: "分布式锁操作失败"}}
+}
+
+func (my *RedisLockError) Error() string { return my.Msg }
+
+func (my *RedisLockError) Is(target error) bool { return reflect.DeepEqual(target, &RedisLockErr) }
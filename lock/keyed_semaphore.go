@@ -0,0 +1,117 @@
+package lock
+
+import (
+	"context"
+	"sync"
+)
+
+type (
+	// KeyedSemaphore 按key的计数信号量：每个key最多size个持有者，key在使用时创建，无人持有或等待时自动清理
+	KeyedSemaphore struct {
+		lock    sync.Mutex
+		size    int
+		entries map[string]*semaphoreEntry
+	}
+
+	// semaphoreEntry 一个key的信号量状态
+	semaphoreEntry struct {
+		refs    int // 持有者和等待者数量：为0时清理
+		holders int
+		wait    chan struct{} // 释放时关闭并替换，用于唤醒等待者
+	}
+)
+
+var KeyedSemaphoreApp KeyedSemaphore
+
+// New 实例化：按key的计数信号量；size最小为1
+func (*KeyedSemaphore) New(size int) *KeyedSemaphore {
+	return &KeyedSemaphore{size: max(size, 1), entries: make(map[string]*semaphoreEntry)}
+}
+
+// Acquire 获取信号量：阻塞直到获取成功或ctx取消；返回的release可重复调用
+func (my *KeyedSemaphore) Acquire(ctx context.Context, key string) (func(), error) {
+	return my.acquire(ctx, key, true)
+}
+
+// TryAcquire 尝试获取信号量：已满时立即返回LockBusyErr
+func (my *KeyedSemaphore) TryAcquire(key string) (func(), error) {
+	return my.acquire(context.Background(), key, false)
+}
+
+// InUse key当前的持有者数量
+func (my *KeyedSemaphore) InUse(key string) int {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	if entry, exists := my.entries[key]; exists {
+		return entry.holders
+	}
+
+	return 0
+}
+
+// Len 当前有持有者或等待者的key数量
+func (my *KeyedSemaphore) Len() int {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	return len(my.entries)
+}
+
+// acquire 获取信号量
+func (my *KeyedSemaphore) acquire(ctx context.Context, key string, block bool) (func(), error) {
+	my.lock.Lock()
+
+	entry, exists := my.entries[key]
+	if !exists {
+		entry = &semaphoreEntry{wait: make(chan struct{})}
+		my.entries[key] = entry
+	}
+	entry.refs++
+
+	for entry.holders >= my.size {
+		if !block {
+			my.unref(key, entry)
+			my.lock.Unlock()
+			return nil, LockBusyErr.New(key)
+		}
+
+		wait := entry.wait
+		my.lock.Unlock()
+
+		select {
+		case <-wait:
+			my.lock.Lock()
+		case <-ctx.Done():
+			my.lock.Lock()
+			my.unref(key, entry)
+			my.lock.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+
+	entry.holders++
+	my.lock.Unlock()
+
+	var once sync.Once
+
+	return func() { once.Do(func() { my.release(key, entry) }) }, nil
+}
+
+// release 释放信号量并唤醒等待者
+func (my *KeyedSemaphore) release(key string, entry *semaphoreEntry) {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	entry.holders--
+	close(entry.wait)
+	entry.wait = make(chan struct{})
+	my.unref(key, entry)
+}
+
+// unref 减少引用，无人持有或等待时清理（调用方需持有锁）
+func (my *KeyedSemaphore) unref(key string, entry *semaphoreEntry) {
+	if entry.refs--; entry.refs == 0 {
+		delete(my.entries, key)
+	}
+}
@@ -0,0 +1,118 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeyedSemaphoreCount(t *testing.T) {
+	sem := KeyedSemaphoreApp.New(2)
+
+	first, err := sem.TryAcquire("k")
+	if err != nil {
+		t.Fatalf("获取信号量失败：%v", err)
+	}
+	second, err := sem.TryAcquire("k")
+	if err != nil {
+		t.Fatalf("获取信号量失败：%v", err)
+	}
+	if sem.InUse("k") != 2 {
+		t.Fatalf("持有者数量错误：%d", sem.InUse("k"))
+	}
+
+	if _, err = sem.TryAcquire("k"); !errors.Is(err, &LockBusyErr) {
+		t.Fatalf("已满时应返回LockBusyErr：%v", err)
+	}
+
+	// 不同key互不影响
+	other, err := sem.TryAcquire("other")
+	if err != nil {
+		t.Fatalf("不同key应可获取：%v", err)
+	}
+	other()
+
+	first()
+	first()
+	if sem.InUse("k") != 1 {
+		t.Fatalf("重复释放只应释放一次：%d", sem.InUse("k"))
+	}
+
+	second()
+	if sem.InUse("k") != 0 || sem.Len() != 0 {
+		t.Fatalf("全部释放后应清理：%d %d", sem.InUse("k"), sem.Len())
+	}
+}
+
+func TestKeyedSemaphoreConcurrency(t *testing.T) {
+	var (
+		sem     = KeyedSemaphoreApp.New(3)
+		wg      sync.WaitGroup
+		holders atomic.Int32
+		peak    atomic.Int32
+	)
+
+	for range 30 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			release, err := sem.Acquire(context.Background(), "k")
+			if err != nil {
+				t.Errorf("获取信号量失败：%v", err)
+				return
+			}
+			defer release()
+
+			current := holders.Add(1)
+			for {
+				if old := peak.Load(); current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+			holders.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 3 {
+		t.Fatalf("持有者数量不应超过size：%d", peak.Load())
+	}
+	if sem.Len() != 0 {
+		t.Fatalf("全部释放后应清理：%d", sem.Len())
+	}
+}
+
+func TestKeyedSemaphoreCancel(t *testing.T) {
+	sem := KeyedSemaphoreApp.New(1)
+
+	release, err := sem.Acquire(context.Background(), "k")
+	if err != nil {
+		t.Fatalf("获取信号量失败：%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err = sem.Acquire(ctx, "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ctx超时应返回DeadlineExceeded：%v", err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		next, err := sem.Acquire(context.Background(), "k")
+		if err != nil {
+			t.Errorf("获取信号量失败：%v", err)
+		}
+		acquired <- next
+	}()
+
+	release()
+	(<-acquired)()
+
+	if sem.Len() != 0 {
+		t.Fatalf("放弃等待和全部释放后应清理：%d", sem.Len())
+	}
+}
@@ -1,26 +1,71 @@
 package lock
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/jericho-yu/aid/dict"
 )
 
 type (
-	// MapLock 字典锁：一个锁的集合
+	// Locker 锁：进程内的MapLock与分布式锁实现相同接口，可以互相替换
+	Locker interface {
+		// Lock 获取锁：阻塞直到获取成功或ctx取消
+		Lock(ctx context.Context, key string) (Lease, error)
+		// TryLock 尝试获取锁：锁被占用时立即返回LockBusyErr
+		TryLock(key string) (Lease, error)
+	}
+
+	// Lease 锁租约：持有者通过租约释放锁；租约过期后锁自动释放，过期的租约无法释放已被其他持有者获取的锁
+	Lease interface {
+		// Key 锁名称
+		Key() string
+		// Token 防护令牌：同一个锁每次获取单调递增，可用于拒绝过期持有者的写入
+		Token() uint64
+		// Unlock 释放锁：租约已失效时返回LeaseExpiredErr
+		Unlock() error
+		// Done 租约失效（释放或过期）时关闭的通道
+		Done() <-chan struct{}
+	}
+
+	// MapLock 字典锁：按key的读写锁，key在使用时创建，无人持有或等待时自动清理
 	MapLock struct {
-		locks *dict.AnyDict[string, *itemLock]
+		lock    sync.Mutex
+		entries map[string]*mapEntry
+		items   map[string]*itemLock // 旧接口通过Set创建的锁项
+		token   atomic.Uint64
+		lease   time.Duration
+	}
+
+	// mapEntry 一个key的锁状态
+	mapEntry struct {
+		refs           int // 持有者和等待者数量：为0时清理
+		writer         uint64
+		readers        map[uint64]struct{}
+		waitingWriters int
+		wait           chan struct{} // 锁状态变化时关闭并替换，用于唤醒等待者
 	}
 
-	// 锁项：一个集合锁中的每一项，包含：锁状态、锁值、超时时间、定时器
+	// mapLease 字典锁租约
+	mapLease struct {
+		mapLock *MapLock
+		key     string
+		token   uint64
+		write   bool
+		timer   *time.Timer
+		done    chan struct{}
+		once    sync.Once
+	}
+
+	// itemLock 锁项（旧接口）：通过Set创建，包含锁值、超时时间、定时器
 	itemLock struct {
-		inUse   bool
+		mapLock *MapLock
 		val     any
 		timeout time.Duration
 		timer   *time.Timer
+		lease   Lease
 	}
 )
 
@@ -37,34 +82,87 @@ func (*MapLock) Once() *MapLock { return OnceMapLock() }
 // NewMapLock 实例化：字典锁
 //
 //go:fix 推荐使用：New方法
-func NewMapLock() *MapLock { return &MapLock{locks: dict.Make[string, *itemLock]()} }
+func NewMapLock() *MapLock {
+	return &MapLock{entries: make(map[string]*mapEntry), items: make(map[string]*itemLock)}
+}
 
 // OnceMapLock 单例化：字典锁
 //
 //go:fix 推荐使用：Once方法
 func OnceMapLock() *MapLock {
-	onceMapLock.Do(func() { mapLockIns = &MapLock{locks: dict.Make[string, *itemLock]()} })
+	onceMapLock.Do(func() { mapLockIns = NewMapLock() })
 
 	return mapLockIns
 }
 
-// Set 创建锁
+// SetLease 设置租约时长：超过时长未释放的锁自动释放，0表示永不过期
+func (my *MapLock) SetLease(lease time.Duration) *MapLock {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.lease = lease
+
+	return my
+}
+
+// Lock 获取写锁：阻塞直到获取成功或ctx取消
+func (my *MapLock) Lock(ctx context.Context, key string) (Lease, error) {
+	return my.acquire(ctx, key, true, true)
+}
+
+// TryLock 尝试获取写锁：锁被占用时立即返回LockBusyErr
+func (my *MapLock) TryLock(key string) (Lease, error) {
+	return my.acquire(context.Background(), key, true, false)
+}
+
+// RLock 获取读锁：阻塞直到获取成功或ctx取消；有写锁等待时新的读锁需等待写锁释放
+func (my *MapLock) RLock(ctx context.Context, key string) (Lease, error) {
+	return my.acquire(ctx, key, false, true)
+}
+
+// TryRLock 尝试获取读锁：锁被占用时立即返回LockBusyErr
+func (my *MapLock) TryRLock(key string) (Lease, error) {
+	return my.acquire(context.Background(), key, false, false)
+}
+
+// Try 检查锁是否空闲：锁被占用时返回LockBusyErr；未通过Set创建且无人持有时返回锁不存在（与旧接口一致）
+func (my *MapLock) Try(key string) error {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	entry, exists := my.entries[key]
+	if exists && (entry.writer != 0 || len(entry.readers) > 0) {
+		return LockBusyErr.New(key)
+	}
+
+	if _, registered := my.items[key]; !registered && !exists {
+		return fmt.Errorf("锁[%s]不存在", key)
+	}
+
+	return nil
+}
+
+// Set 创建锁（旧接口）：val保存在锁项中
+//
+//go:fix 推荐使用：Lock方法，锁在使用时自动创建
 func (my *MapLock) Set(key string, val any) error {
-	_, exists := my.locks.Get(key)
-	if exists {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	if _, exists := my.items[key]; exists {
 		return fmt.Errorf("锁[%s]已存在", key)
-	} else {
-		my.locks.Set(key, &itemLock{val: val})
 	}
+	my.items[key] = &itemLock{mapLock: my, val: val}
 
 	return nil
 }
 
-// SetMany 批量创建锁
+// SetMany 批量创建锁（旧接口）：任意一个失败时删除所有锁
+//
+//go:fix 推荐使用：Lock方法，锁在使用时自动创建
 func (my *MapLock) SetMany(items map[string]any) error {
 	for idx, item := range items {
-		err := my.Set(idx, item)
-		if err != nil {
+		if err := my.Set(idx, item); err != nil {
 			my.DestroyAll()
 			return err
 		}
@@ -73,100 +171,251 @@ func (my *MapLock) SetMany(items map[string]any) error {
 	return nil
 }
 
-// Release 显式锁释放方法
-func (r *itemLock) Release() {
-	if r.timer != nil {
-		r.timer.Stop()
-		r.timer = nil
-	}
-	r.inUse = false
-}
-
-// Destroy 删除锁
+// Destroy 删除锁（旧接口）：释放通过LockTimeout获取的锁
+//
+//go:fix 推荐使用：Lease.Unlock方法，无人持有的锁自动清理
 func (my *MapLock) Destroy(key string) {
-	if il, ok := my.locks.Get(key); ok {
-		il.Release()
-		my.locks.RemoveByKey(key) // 删除键值对，以便垃圾回收
+	my.lock.Lock()
+	item, exists := my.items[key]
+	delete(my.items, key)
+	my.lock.Unlock()
+
+	if exists {
+		item.Release()
 	}
 }
 
-// DestroyAll 删除所有锁
+// DestroyAll 删除所有锁（旧接口）
+//
+//go:fix 推荐使用：Lease.Unlock方法，无人持有的锁自动清理
 func (my *MapLock) DestroyAll() {
-	my.locks.Each(func(key string, value *itemLock) {
+	my.lock.Lock()
+	keys := make([]string, 0, len(my.items))
+	for key := range my.items {
+		keys = append(keys, key)
+	}
+	my.lock.Unlock()
+
+	for _, key := range keys {
 		my.Destroy(key)
-	})
+	}
 }
 
-// Lock 获取锁
-func (my *MapLock) Lock(key string, timeout time.Duration) (*itemLock, error) {
-	if item, exists := my.locks.Get(key); !exists {
+// LockTimeout 获取锁（旧接口Lock(key, timeout)）：key需要先通过Set创建，锁被占用时立即返回LockBusyErr；timeout大于0时超时自动释放
+//
+//go:fix 推荐使用：Lock方法
+func (my *MapLock) LockTimeout(key string, timeout time.Duration) (*itemLock, error) {
+	my.lock.Lock()
+	item, exists := my.items[key]
+	my.lock.Unlock()
+
+	if !exists {
 		return nil, fmt.Errorf("锁[%s]不存在", key)
-	} else {
-		if item.inUse {
-			return nil, fmt.Errorf("锁[%s]被占用", key)
+	}
+
+	lease, err := my.TryLock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	my.lock.Lock()
+	item.lease, item.timeout = lease, timeout
+	if timeout > 0 {
+		item.timer = time.AfterFunc(timeout, func() { item.release(lease) })
+	}
+	my.lock.Unlock()
+
+	return item, nil
+}
+
+// Len 当前有持有者或等待者的key数量
+func (my *MapLock) Len() int {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	return len(my.entries)
+}
+
+// acquire 获取锁
+func (my *MapLock) acquire(ctx context.Context, key string, write, block bool) (Lease, error) {
+	my.lock.Lock()
+
+	entry, exists := my.entries[key]
+	if !exists {
+		entry = &mapEntry{readers: make(map[uint64]struct{}), wait: make(chan struct{})}
+		my.entries[key] = entry
+	}
+	entry.refs++
+	if write {
+		entry.waitingWriters++
+	}
+
+	for {
+		if write && entry.writer == 0 && len(entry.readers) == 0 {
+			entry.waitingWriters--
+			break
+		}
+		// 写锁优先：有写锁等待时新的读锁需等待
+		if !write && entry.writer == 0 && entry.waitingWriters == 0 {
+			break
+		}
+
+		if !block {
+			my.abandon(key, entry, write)
+			my.lock.Unlock()
+			return nil, LockBusyErr.New(key)
 		}
 
-		// 设置锁占用
-		item.inUse = true
-
-		// 设置超时时间
-		if timeout > 0 {
-			item.timeout = timeout
-			item.timer = time.AfterFunc(timeout, func() {
-				if il, ok := my.locks.Get(key); ok {
-					if il.timer != nil {
-						il.Release()
-					}
-				}
-			})
+		wait := entry.wait
+		my.lock.Unlock()
+
+		select {
+		case <-wait:
+			my.lock.Lock()
+		case <-ctx.Done():
+			my.lock.Lock()
+			my.abandon(key, entry, write)
+			my.lock.Unlock()
+			return nil, ctx.Err()
 		}
+	}
 
-		return item, nil
+	token := my.token.Add(1)
+	if write {
+		entry.writer = token
+	} else {
+		entry.readers[token] = struct{}{}
 	}
+
+	lease := &mapLease{mapLock: my, key: key, token: token, write: write, done: make(chan struct{})}
+	if my.lease > 0 {
+		lease.timer = time.AfterFunc(my.lease, func() { lease.release(false) })
+	}
+	my.lock.Unlock()
+
+	return lease, nil
 }
 
-// Try 尝试获取锁
-func (my *MapLock) Try(key string) error {
-	if item, exist := my.locks.Get(key); !exist {
-		return fmt.Errorf("锁[%s]不存在", key)
+// abandon 放弃等待（调用方需持有锁）
+func (my *MapLock) abandon(key string, entry *mapEntry, write bool) {
+	if write {
+		entry.waitingWriters--
+		// 等待中的写锁会阻塞新的读锁，放弃时需唤醒
+		entry.broadcast()
+	}
+
+	my.unref(key, entry)
+}
+
+// release 释放令牌对应的锁：令牌已不是当前持有者时返回false
+func (my *MapLock) release(key string, token uint64, write bool) bool {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	entry, exists := my.entries[key]
+	if !exists {
+		return false
+	}
+
+	if write {
+		if entry.writer != token {
+			return false
+		}
+		entry.writer = 0
 	} else {
-		if item.inUse {
-			return fmt.Errorf("锁[%s]被占用", key)
+		if _, held := entry.readers[token]; !held {
+			return false
 		}
-		return nil
+		delete(entry.readers, token)
 	}
+
+	entry.broadcast()
+	my.unref(key, entry)
+
+	return true
 }
 
-func DemoMapLock() {
-	k8sLinks := map[string]any{
-		"k8s-a": &struct{}{},
-		"k8s-b": &struct{}{},
-		"k8s-c": &struct{}{},
+// unref 减少引用，无人持有或等待时清理（调用方需持有锁）
+func (my *MapLock) unref(key string, entry *mapEntry) {
+	if entry.refs--; entry.refs == 0 {
+		delete(my.entries, key)
+	}
+}
+
+// broadcast 唤醒所有等待者
+func (my *mapEntry) broadcast() {
+	close(my.wait)
+	my.wait = make(chan struct{})
+}
+
+func (my *mapLease) Key() string { return my.key }
+
+func (my *mapLease) Token() uint64 { return my.token }
+
+func (my *mapLease) Done() <-chan struct{} { return my.done }
+
+// Unlock 释放锁：租约已过期时返回LeaseExpiredErr
+func (my *mapLease) Unlock() error {
+	if !my.release(true) {
+		return LeaseExpiredErr.New(my.key)
 	}
 
-	// 获取字典锁对象
-	ml := OnceMapLock()
+	return nil
+}
+
+// release 释放锁并关闭done：只有第一次调用且令牌仍有效时返回true；过期回调中不能访问timer
+func (my *mapLease) release(stopTimer bool) bool {
+	released := false
+
+	my.once.Do(func() {
+		if stopTimer && my.timer != nil {
+			my.timer.Stop()
+		}
+		released = my.mapLock.release(my.key, my.token, my.write)
+		close(my.done)
+	})
+
+	return released
+}
+
+// Release 显式锁释放方法（旧接口）
+//
+//go:fix 推荐使用：Lease.Unlock方法
+func (r *itemLock) Release() { r.release(nil) }
+
+// GetVal 获取锁值
+func (r *itemLock) GetVal() any { return r.val }
 
-	// 批量创建锁
-	storeErr := ml.SetMany(k8sLinks)
-	if storeErr != nil {
-		// 处理err
-		log.Fatalln(storeErr.Error())
+// release 释放锁：lease不为nil时只释放该租约，避免过期回调释放之后重新获取的锁
+func (r *itemLock) release(lease Lease) {
+	r.mapLock.lock.Lock()
+	if r.lease == nil || (lease != nil && r.lease != lease) {
+		r.mapLock.lock.Unlock()
+		return
 	}
+	held, timer := r.lease, r.timer
+	r.lease, r.timer = nil, nil
+	r.mapLock.lock.Unlock()
 
-	// 检测锁
-	tryErr := ml.Try("k8s-a")
-	if tryErr != nil {
-		// 处理err
-		log.Fatalln(tryErr.Error())
+	if timer != nil {
+		timer.Stop()
 	}
+	_ = held.Unlock()
+}
+
+func DemoMapLock() {
+	// 获取字典锁对象：10秒业务处理不完也会过期，设置为0则为永不过期
+	ml := OnceMapLock().SetLease(time.Second * 10)
+
+	// 获取锁：最多等待3秒
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
 
-	// 获取锁
-	lock, lockErr := ml.Lock("k8s-a", time.Second*10) // 10秒业务处理不完也会过期 设置为：0则为永不过期
+	lease, lockErr := ml.Lock(ctx, "k8s-a")
 	if lockErr != nil {
 		log.Fatalln(lockErr.Error())
 	}
-	defer lock.Release()
+	defer func() { _ = lease.Unlock() }()
 
 	// 处理业务...
 }
@@ -0,0 +1,271 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitWriters 等待指定数量的写锁进入等待
+func waitWriters(t *testing.T, ml *MapLock, key string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		ml.lock.Lock()
+		entry, exists := ml.entries[key]
+		waiting := exists && entry.waitingWriters == n
+		ml.lock.Unlock()
+		if waiting {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("写锁没有进入等待：%s", key)
+}
+
+func TestMapLockWritePreference(t *testing.T) {
+	ml := NewMapLock()
+
+	reader, err := ml.RLock(context.Background(), "k")
+	if err != nil {
+		t.Fatalf("获取读锁失败：%v", err)
+	}
+
+	if _, err = ml.TryLock("k"); !errors.Is(err, &LockBusyErr) {
+		t.Fatalf("读锁持有时写锁应返回LockBusyErr：%v", err)
+	}
+
+	acquired := make(chan Lease)
+	go func() {
+		writer, err := ml.Lock(context.Background(), "k")
+		if err != nil {
+			t.Errorf("获取写锁失败：%v", err)
+		}
+		acquired <- writer
+	}()
+	waitWriters(t, ml, "k", 1)
+
+	// 写锁等待时新的读锁需要等待
+	if _, err = ml.TryRLock("k"); !errors.Is(err, &LockBusyErr) {
+		t.Fatalf("写锁等待时新的读锁应返回LockBusyErr：%v", err)
+	}
+
+	readerAcquired := make(chan Lease)
+	go func() {
+		lease, err := ml.RLock(context.Background(), "k")
+		if err != nil {
+			t.Errorf("获取读锁失败：%v", err)
+		}
+		readerAcquired <- lease
+	}()
+
+	if err = reader.Unlock(); err != nil {
+		t.Fatalf("释放读锁失败：%v", err)
+	}
+
+	writer := <-acquired
+	select {
+	case <-readerAcquired:
+		t.Fatal("写锁持有时不应获取读锁")
+	default:
+	}
+
+	if err = writer.Unlock(); err != nil {
+		t.Fatalf("释放写锁失败：%v", err)
+	}
+	if err = (<-readerAcquired).Unlock(); err != nil {
+		t.Fatalf("释放读锁失败：%v", err)
+	}
+
+	if ml.Len() != 0 {
+		t.Fatalf("所有锁释放后应清理：%d", ml.Len())
+	}
+}
+
+func TestMapLockSharedReaders(t *testing.T) {
+	ml := NewMapLock()
+
+	first, err := ml.TryRLock("k")
+	if err != nil {
+		t.Fatalf("获取读锁失败：%v", err)
+	}
+	second, err := ml.TryRLock("k")
+	if err != nil {
+		t.Fatalf("读锁应可共享：%v", err)
+	}
+
+	_ = first.Unlock()
+	_ = second.Unlock()
+
+	if err = first.Unlock(); !errors.Is(err, &LeaseExpiredErr) {
+		t.Fatalf("重复释放应返回LeaseExpiredErr：%v", err)
+	}
+}
+
+func TestMapLockContextCancel(t *testing.T) {
+	ml := NewMapLock()
+
+	holder, err := ml.Lock(context.Background(), "k")
+	if err != nil {
+		t.Fatalf("获取锁失败：%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err = ml.Lock(ctx, "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ctx超时应返回DeadlineExceeded：%v", err)
+	}
+
+	// 放弃等待的写锁不应阻塞读锁
+	_ = holder.Unlock()
+	reader, err := ml.TryRLock("k")
+	if err != nil {
+		t.Fatalf("放弃等待后应可获取读锁：%v", err)
+	}
+	_ = reader.Unlock()
+
+	if ml.Len() != 0 {
+		t.Fatalf("所有锁释放后应清理：%d", ml.Len())
+	}
+}
+
+func TestMapLockLeaseExpiry(t *testing.T) {
+	ml := NewMapLock().SetLease(20 * time.Millisecond)
+
+	expired, err := ml.Lock(context.Background(), "k")
+	if err != nil {
+		t.Fatalf("获取锁失败：%v", err)
+	}
+
+	select {
+	case <-expired.Done():
+	case <-time.After(time.Second):
+		t.Fatal("租约应过期")
+	}
+
+	ml.SetLease(0)
+	current, err := ml.TryLock("k")
+	if err != nil {
+		t.Fatalf("租约过期后应可重新获取：%v", err)
+	}
+
+	// 过期的租约不能释放其他持有者重新获取的锁
+	if err = expired.Unlock(); !errors.Is(err, &LeaseExpiredErr) {
+		t.Fatalf("过期租约释放应返回LeaseExpiredErr：%v", err)
+	}
+	if err = ml.Try("k"); !errors.Is(err, &LockBusyErr) {
+		t.Fatalf("新的持有者应仍持有锁：%v", err)
+	}
+
+	if err = current.Unlock(); err != nil {
+		t.Fatalf("释放锁失败：%v", err)
+	}
+}
+
+func TestMapLockFencingToken(t *testing.T) {
+	var (
+		ml     = NewMapLock()
+		wg     sync.WaitGroup
+		tokens []uint64
+	)
+
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for range 20 {
+				lease, err := ml.Lock(context.Background(), "k")
+				if err != nil {
+					t.Errorf("获取锁失败：%v", err)
+					return
+				}
+				// 持有锁时追加，令牌顺序即为获取顺序
+				tokens = append(tokens, lease.Token())
+				_ = lease.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(tokens) != 400 {
+		t.Fatalf("获取次数错误：%d", len(tokens))
+	}
+	for idx := 1; idx < len(tokens); idx++ {
+		if tokens[idx] <= tokens[idx-1] {
+			t.Fatalf("防护令牌应严格递增：%d <= %d", tokens[idx], tokens[idx-1])
+		}
+	}
+
+	other, _ := ml.TryLock("other")
+	if other.Token() <= tokens[len(tokens)-1] {
+		t.Fatalf("不同key的防护令牌也应递增：%d", other.Token())
+	}
+	_ = other.Unlock()
+}
+
+func TestMapLockLegacy(t *testing.T) {
+	ml := NewMapLock()
+
+	if _, err := ml.LockTimeout("k", 0); err == nil {
+		t.Fatal("未创建的锁应返回错误")
+	}
+	if err := ml.Try("k"); err == nil {
+		t.Fatal("未创建的锁检查应返回错误")
+	}
+
+	if err := ml.SetMany(map[string]any{"k": "v", "other": 1}); err != nil {
+		t.Fatalf("创建锁失败：%v", err)
+	}
+	if err := ml.Set("k", "v"); err == nil {
+		t.Fatal("重复创建应返回错误")
+	}
+	if err := ml.Try("k"); err != nil {
+		t.Fatalf("已创建的空闲锁检查应通过：%v", err)
+	}
+
+	item, err := ml.LockTimeout("k", 0)
+	if err != nil {
+		t.Fatalf("获取锁失败：%v", err)
+	}
+	if item.GetVal() != "v" {
+		t.Fatalf("锁值错误：%v", item.GetVal())
+	}
+	if _, err = ml.LockTimeout("k", 0); !errors.Is(err, &LockBusyErr) {
+		t.Fatalf("锁被占用时应返回LockBusyErr：%v", err)
+	}
+
+	item.Release()
+	item.Release()
+	if err = ml.Try("k"); err != nil {
+		t.Fatalf("释放后锁应空闲：%v", err)
+	}
+
+	// 超时自动释放
+	if item, err = ml.LockTimeout("k", 20*time.Millisecond); err != nil {
+		t.Fatalf("获取锁失败：%v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for ml.Try("k") != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("超时后锁应自动释放")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 删除时释放持有的锁
+	if _, err = ml.LockTimeout("other", 0); err != nil {
+		t.Fatalf("获取锁失败：%v", err)
+	}
+	ml.DestroyAll()
+	if _, err = ml.LockTimeout("other", 0); err == nil {
+		t.Fatal("删除后的锁应返回错误")
+	}
+	if ml.Len() != 0 {
+		t.Fatalf("删除后应清理：%d", ml.Len())
+	}
+}
@@ -0,0 +1,293 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	mathRand "math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jericho-yu/aid/redisPool"
+	rds "github.com/redis/go-redis/v9"
+)
+
+type (
+	// RedisLock redis分布式锁：SET NX PX加锁，比较持有者后删除解锁，持有期间自动续期
+	RedisLock struct {
+		prefix        string
+		client        *rds.Client
+		ttl           time.Duration
+		retryInterval time.Duration
+		timeout       time.Duration
+	}
+
+	// redisLease redis分布式锁租约
+	redisLease struct {
+		redisLock *RedisLock
+		key       string
+		owner     string
+		token     uint64
+		done      chan struct{}
+		stop      chan struct{}
+		acquired  time.Time
+		once      sync.Once
+		lost      sync.Once
+	}
+)
+
+var (
+	RedisLockApp RedisLock
+
+	_ Locker = (*MapLock)(nil)
+	_ Locker = (*RedisLock)(nil)
+
+	// acquireScript 加锁：成功时递增并返回防护令牌，失败时返回0
+	acquireScript = rds.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`)
+
+	// releaseScript 解锁：只有持有者可以删除
+	releaseScript = rds.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+	// renewScript 续期：只有持有者可以续期
+	renewScript = rds.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+)
+
+// New 实例化：redis分布式锁；clientName为redisPool中的链接名
+//
+// 默认租约30秒，持有期间每10秒续期；阻塞获取时每50毫秒重试；单次redis操作超时3秒
+func (*RedisLock) New(pool *redisPool.RedisPool, clientName string) *RedisLock {
+	prefix, client := pool.GetClient(clientName)
+
+	return &RedisLock{
+		prefix:        fmt.Sprintf("%s:lock", prefix),
+		client:        client,
+		ttl:           30 * time.Second,
+		retryInterval: 50 * time.Millisecond,
+		timeout:       3 * time.Second,
+	}
+}
+
+// SetTtl 设置租约时长：持有者存活时每隔ttl/3自动续期，持有者崩溃后最多ttl后锁自动释放
+func (my *RedisLock) SetTtl(ttl time.Duration) *RedisLock {
+	my.ttl = ttl
+
+	return my
+}
+
+// SetRetryInterval 设置阻塞获取时的重试间隔
+func (my *RedisLock) SetRetryInterval(retryInterval time.Duration) *RedisLock {
+	my.retryInterval = retryInterval
+
+	return my
+}
+
+// SetTimeout 设置单次redis操作超时
+func (my *RedisLock) SetTimeout(timeout time.Duration) *RedisLock {
+	my.timeout = timeout
+
+	return my
+}
+
+// Lock 获取锁：阻塞直到获取成功或ctx取消；redis网络错误或超时时退避重试，配置错误和redis返回的其他错误立即返回
+func (my *RedisLock) Lock(ctx context.Context, key string) (Lease, error) {
+	backoff := my.retryInterval
+	for {
+		lease, retryable, err := my.acquire(ctx, key)
+		switch {
+		case lease != nil:
+			return lease, nil
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case err != nil && !retryable:
+			return nil, err
+		case err != nil:
+			// redis临时错误：重试间隔加倍，最多不超过单次操作超时
+			backoff = min(backoff*2, max(my.timeout, my.retryInterval))
+		default:
+			backoff = my.retryInterval
+		}
+
+		// 增加随机抖动，避免多个等待者同时重试
+		wait := backoff + time.Duration(mathRand.Int63n(int64(backoff/2)+1))
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// TryLock 尝试获取锁：锁被占用时立即返回LockBusyErr
+func (my *RedisLock) TryLock(key string) (Lease, error) {
+	lease, _, err := my.acquire(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	if lease == nil {
+		return nil, LockBusyErr.New(key)
+	}
+
+	return lease, nil
+}
+
+// acquire 尝试加锁一次：锁被占用时返回nil；retryable表示错误是否为可重试的临时错误
+func (my *RedisLock) acquire(ctx context.Context, key string) (lease *redisLease, retryable bool, err error) {
+	if my.client == nil {
+		return nil, false, RedisLockErr.New("redis链接不存在")
+	}
+	if my.ttl < time.Millisecond {
+		return nil, false, RedisLockErr.New(fmt.Sprintf("租约时长不能小于1毫秒：%s", my.ttl))
+	}
+
+	owner, err := newOwner()
+	if err != nil {
+		return nil, false, RedisLockErr.Wrap(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, my.timeout)
+	defer cancel()
+
+	acquired := time.Now()
+	token, err := acquireScript.Run(ctx, my.client, []string{my.lockKey(key), my.fenceKey(key)}, owner, my.ttl.Milliseconds()).Uint64()
+	if err != nil {
+		return nil, isTemporary(err), RedisLockErr.Wrap(err)
+	}
+	if token == 0 {
+		return nil, false, nil
+	}
+
+	lease = &redisLease{redisLock: my, key: key, owner: owner, token: token, done: make(chan struct{}), stop: make(chan struct{}), acquired: acquired}
+	go lease.keepAlive()
+
+	return lease, false, nil
+}
+
+// lockKey 锁的redis key：使用hash tag保证锁与防护令牌在同一个槽
+func (my *RedisLock) lockKey(key string) string { return fmt.Sprintf("%s:{%s}", my.prefix, key) }
+
+// fenceKey 防护令牌的redis key
+func (my *RedisLock) fenceKey(key string) string { return fmt.Sprintf("%s:{%s}:fence", my.prefix, key) }
+
+// newOwner 生成持有者标识
+func newOwner() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// isTemporary 判断redis错误是否可重试：网络错误、超时以及redis加载中、主从切换等临时状态
+func isTemporary(err error) bool {
+	var redisErr rds.Error
+	if errors.As(err, &redisErr) {
+		for _, prefix := range []string{"LOADING", "READONLY", "CLUSTERDOWN", "TRYAGAIN", "MASTERDOWN"} {
+			if rds.HasErrorPrefix(err, prefix) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func (my *redisLease) Key() string { return my.key }
+
+func (my *redisLease) Token() uint64 { return my.token }
+
+func (my *redisLease) Done() <-chan struct{} { return my.done }
+
+// Unlock 释放锁：锁已过期或被其他持有者获取时返回LeaseExpiredErr
+func (my *redisLease) Unlock() error {
+	var err error
+
+	my.once.Do(func() {
+		close(my.stop)
+		defer my.expire()
+
+		ctx, cancel := context.WithTimeout(context.Background(), my.redisLock.timeout)
+		defer cancel()
+
+		released, e := releaseScript.Run(ctx, my.redisLock.client, []string{my.redisLock.lockKey(my.key)}, my.owner).Int64()
+		if e != nil {
+			err = RedisLockErr.Wrap(e)
+			return
+		}
+		if released == 0 {
+			err = LeaseExpiredErr.New(my.key)
+		}
+	})
+
+	return err
+}
+
+// keepAlive 自动续期：续期失败（锁已不属于自己）或无法在租约到期前完成续期时，租约失效
+//
+// 租约到期时间从最近一次成功续期的发起时间算起，并预留ttl/10的余量：保证Done在其他持有者能获取锁之前关闭
+func (my *redisLease) keepAlive() {
+	ttl := my.redisLock.ttl
+	ticker := time.NewTicker(max(ttl/3, time.Millisecond))
+	defer ticker.Stop()
+
+	deadline := my.acquired.Add(ttl - ttl/10)
+	expiry := time.NewTimer(time.Until(deadline))
+	defer expiry.Stop()
+
+	for {
+		select {
+		case <-my.stop:
+			return
+		case <-expiry.C:
+			my.expire()
+			return
+		case <-ticker.C:
+			// 单次续期不能超过租约到期时间：避免续期阻塞期间锁已被其他持有者获取
+			start := time.Now()
+			renewDeadline := start.Add(my.redisLock.timeout)
+			if deadline.Before(renewDeadline) {
+				renewDeadline = deadline
+			}
+			ctx, cancel := context.WithDeadline(context.Background(), renewDeadline)
+			ok, err := renewScript.Run(ctx, my.redisLock.client, []string{my.redisLock.lockKey(my.key)}, my.owner, ttl.Milliseconds()).Int64()
+			cancel()
+
+			switch {
+			case err == nil && ok == 1:
+				deadline = start.Add(ttl - ttl/10)
+				expiry.Reset(time.Until(deadline))
+			case err == nil:
+				my.expire()
+				return
+			}
+		}
+	}
+}
+
+// expire 租约失效
+func (my *redisLease) expire() { my.lost.Do(func() { close(my.done) }) }
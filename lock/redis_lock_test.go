@@ -0,0 +1,268 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jericho-yu/aid/redisPool"
+)
+
+var (
+	miniRedisOnce sync.Once
+	miniRedisIns  *miniredis.Miniredis
+)
+
+// newTestRedisPool 使用miniredis创建redis链接池：链接池为单例，所有测试共享同一个miniredis
+func newTestRedisPool(t *testing.T) (*redisPool.RedisPool, *miniredis.Miniredis) {
+	miniRedisOnce.Do(func() {
+		var err error
+		if miniRedisIns, err = miniredis.Run(); err != nil {
+			t.Fatalf("启动miniredis失败：%v", err)
+		}
+
+		filename := filepath.Join(os.TempDir(), fmt.Sprintf("lock-redis-%d.yaml", os.Getpid()))
+		content := fmt.Sprintf("host: %s\nport: %s\nprefix: test\npool:\n  - key: lock\n    prefix: lock\n    dbNum: 0\n", miniRedisIns.Host(), miniRedisIns.Port())
+		if err = os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatalf("写入redis配置失败：%v", err)
+		}
+		defer func() { _ = os.Remove(filename) }()
+
+		redisPool.RedisPoolApp.Once(redisPool.RedisSettingApp.New(filename))
+	})
+	miniRedisIns.SetError("")
+	miniRedisIns.FlushAll()
+
+	return redisPool.RedisPoolApp.Once(nil), miniRedisIns
+}
+
+func TestRedisLockClient(t *testing.T) {
+	pool, _ := newTestRedisPool(t)
+
+	if _, err := RedisLockApp.New(pool, "not-exists").TryLock("k"); !errors.Is(err, &RedisLockErr) {
+		t.Fatalf("链接不存在时应返回RedisLockErr：%v", err)
+	}
+}
+
+func TestRedisLockFencingToken(t *testing.T) {
+	pool, _ := newTestRedisPool(t)
+	redisLock := RedisLockApp.New(pool, "lock")
+
+	var last uint64
+	for range 5 {
+		lease, err := redisLock.TryLock("k")
+		if err != nil {
+			t.Fatalf("获取锁失败：%v", err)
+		}
+		if lease.Token() <= last {
+			t.Fatalf("防护令牌应严格递增：%d <= %d", lease.Token(), last)
+		}
+		last = lease.Token()
+
+		if _, err = redisLock.TryLock("k"); !errors.Is(err, &LockBusyErr) {
+			t.Fatalf("锁被占用时应返回LockBusyErr：%v", err)
+		}
+		if err = lease.Unlock(); err != nil {
+			t.Fatalf("释放锁失败：%v", err)
+		}
+	}
+}
+
+func TestRedisLockRelease(t *testing.T) {
+	pool, mr := newTestRedisPool(t)
+	redisLock := RedisLockApp.New(pool, "lock").SetTtl(time.Minute)
+
+	expired, err := redisLock.TryLock("k")
+	if err != nil {
+		t.Fatalf("获取锁失败：%v", err)
+	}
+
+	// 租约到期后被其他持有者获取
+	mr.FastForward(2 * time.Minute)
+	current, err := redisLock.TryLock("k")
+	if err != nil {
+		t.Fatalf("租约过期后应可重新获取：%v", err)
+	}
+
+	// 比较持有者后删除：过期租约不能删除新持有者的锁
+	if err = expired.Unlock(); !errors.Is(err, &LeaseExpiredErr) {
+		t.Fatalf("过期租约释放应返回LeaseExpiredErr：%v", err)
+	}
+	if !mr.Exists(redisLock.lockKey("k")) {
+		t.Fatal("新持有者的锁不应被删除")
+	}
+	select {
+	case <-expired.Done():
+	default:
+		t.Fatal("释放后租约应失效")
+	}
+
+	if err = current.Unlock(); err != nil {
+		t.Fatalf("释放锁失败：%v", err)
+	}
+	if mr.Exists(redisLock.lockKey("k")) {
+		t.Fatal("释放后锁应被删除")
+	}
+}
+
+func TestRedisLockKeepAlive(t *testing.T) {
+	pool, mr := newTestRedisPool(t)
+	redisLock := RedisLockApp.New(pool, "lock").SetTtl(90 * time.Millisecond)
+	key := redisLock.lockKey("k")
+
+	lease, err := redisLock.TryLock("k")
+	if err != nil {
+		t.Fatalf("获取锁失败：%v", err)
+	}
+
+	// miniredis的过期时间只随FastForward推进：推进后等待续期把过期时间重置为ttl
+	for range 3 {
+		mr.FastForward(80 * time.Millisecond)
+
+		deadline := time.Now().Add(time.Second)
+		for mr.TTL(key) <= 10*time.Millisecond {
+			if time.Now().After(deadline) {
+				t.Fatalf("持有期间应自动续期：%v", mr.TTL(key))
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	if !mr.Exists(key) {
+		t.Fatal("续期后锁应仍存在")
+	}
+
+	// 锁被删除后续期失败，租约失效
+	mr.Del(key)
+	select {
+	case <-lease.Done():
+	case <-time.After(time.Second):
+		t.Fatal("续期失败后租约应失效")
+	}
+	if err = lease.Unlock(); !errors.Is(err, &LeaseExpiredErr) {
+		t.Fatalf("失效租约释放应返回LeaseExpiredErr：%v", err)
+	}
+}
+
+func TestRedisLockLeaseLostBeforeExpiry(t *testing.T) {
+	pool, mr := newTestRedisPool(t)
+	ttl := 300 * time.Millisecond
+	redisLock := RedisLockApp.New(pool, "lock").SetTtl(ttl).SetTimeout(20 * time.Millisecond)
+
+	lease, err := redisLock.TryLock("k")
+	if err != nil {
+		t.Fatalf("获取锁失败：%v", err)
+	}
+
+	// 续期持续失败：最近一次成功续期不晚于此刻，租约应在锁过期（此刻+ttl）之前失效
+	mr.SetError("ERR 续期失败")
+	defer mr.SetError("")
+	stopped := time.Now()
+
+	select {
+	case <-lease.Done():
+	case <-time.After(time.Second):
+		t.Fatal("续期失败后租约应失效")
+	}
+	if elapsed := time.Since(stopped); elapsed >= ttl {
+		t.Fatalf("租约应在锁过期之前失效：%v", elapsed)
+	}
+}
+
+func TestRedisLockFailFast(t *testing.T) {
+	pool, mr := newTestRedisPool(t)
+
+	tests := []struct {
+		name      string
+		redisLock *RedisLock
+		setup     func()
+	}{
+		{"链接不存在", RedisLockApp.New(pool, "not-exists"), func() {}},
+		{"租约时长小于1毫秒", RedisLockApp.New(pool, "lock").SetTtl(time.Microsecond), func() {}},
+		{"认证错误", RedisLockApp.New(pool, "lock"), func() { mr.SetError("NOAUTH Authentication required.") }},
+		{"类型错误", RedisLockApp.New(pool, "lock"), func() { mr.SetError("WRONGTYPE Operation against a key holding the wrong kind of value") }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.setup()
+			defer mr.SetError("")
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			if _, err := test.redisLock.Lock(ctx, "k"); !errors.Is(err, &RedisLockErr) {
+				t.Fatalf("不可重试的错误应立即返回RedisLockErr：%v", err)
+			}
+		})
+	}
+}
+
+func TestRedisLockRetry(t *testing.T) {
+	pool, mr := newTestRedisPool(t)
+	redisLock := RedisLockApp.New(pool, "lock").SetRetryInterval(5 * time.Millisecond).SetTimeout(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// redis加载数据时持续重试，加载完成后获取成功
+	mr.SetError("LOADING Redis is loading the dataset in memory")
+	time.AfterFunc(100*time.Millisecond, func() { mr.SetError("") })
+
+	lease, err := redisLock.Lock(ctx, "k")
+	if err != nil {
+		t.Fatalf("redis恢复后应获取成功：%v", err)
+	}
+	if err = lease.Unlock(); err != nil {
+		t.Fatalf("释放锁失败：%v", err)
+	}
+
+	// 网络错误时持续重试，redis重启后获取成功
+	mr.Close()
+	restarted := make(chan struct{})
+	time.AfterFunc(100*time.Millisecond, func() {
+		defer close(restarted)
+		if err := mr.Restart(); err != nil {
+			t.Errorf("重启miniredis失败：%v", err)
+		}
+	})
+
+	if lease, err = redisLock.Lock(ctx, "k"); err != nil {
+		t.Fatalf("网络恢复后应获取成功：%v", err)
+	}
+	<-restarted
+
+	// 锁被占用时阻塞直到ctx取消
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer waitCancel()
+	if _, err = redisLock.Lock(waitCtx, "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ctx超时应返回DeadlineExceeded：%v", err)
+	}
+
+	// 网络持续错误时只在ctx取消后返回
+	mr.Close()
+	errCtx, errCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer errCancel()
+	if _, err = redisLock.Lock(errCtx, "other"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ctx超时应返回DeadlineExceeded：%v", err)
+	}
+	if err = mr.Restart(); err != nil {
+		t.Fatalf("重启miniredis失败：%v", err)
+	}
+
+	// 链接池连续拨号失败后会暂停拨号：重试直到链接池恢复
+	other, err := redisLock.Lock(ctx, "other")
+	if err != nil {
+		t.Fatalf("网络恢复后应获取成功：%v", err)
+	}
+	for _, l := range []Lease{lease, other} {
+		if err = l.Unlock(); err != nil {
+			t.Fatalf("释放锁失败：%v", err)
+		}
+	}
+}
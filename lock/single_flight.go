@@ -0,0 +1,123 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// SingleFlight 合并请求：同一个key并发调用时只执行一次，所有调用方共享结果；可选缓存成功结果
+	SingleFlight[T any] struct {
+		lock  sync.Mutex
+		calls map[string]*flightCall[T]
+		cache time.Duration
+	}
+
+	// flightCall 一个key正在执行或已缓存的调用
+	flightCall[T any] struct {
+		done    chan struct{}
+		cancel  context.CancelFunc
+		waiters int // 等待中的调用方数量：全部放弃时取消执行
+		val     T
+		err     error
+		expires time.Time
+	}
+)
+
+// NewSingleFlight 实例化：合并请求
+func NewSingleFlight[T any]() *SingleFlight[T] {
+	return &SingleFlight[T]{calls: make(map[string]*flightCall[T])}
+}
+
+// SetCache 设置成功结果的缓存时长：缓存期内的调用直接返回缓存结果，0表示不缓存
+func (my *SingleFlight[T]) SetCache(cache time.Duration) *SingleFlight[T] {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.cache = cache
+
+	return my
+}
+
+// Do 执行fn：同一个key已有执行中或已缓存的调用时等待并共享其结果，shared表示结果是否来自其他调用
+//
+// 每个调用方按自己的ctx等待，ctx取消时返回ctx的错误；所有调用方都放弃时取消fn的ctx
+func (my *SingleFlight[T]) Do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (val T, shared bool, err error) {
+	my.lock.Lock()
+
+	call, exists := my.calls[key]
+	if exists && !call.expires.IsZero() && time.Now().After(call.expires) {
+		delete(my.calls, key)
+		exists = false
+	}
+
+	if !exists {
+		flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall[T]{done: make(chan struct{}), cancel: cancel}
+		my.calls[key] = call
+		go my.execute(flightCtx, key, call, fn)
+	}
+	call.waiters++
+	my.lock.Unlock()
+
+	select {
+	case <-call.done:
+		return call.val, exists, call.err
+	case <-ctx.Done():
+		my.lock.Lock()
+		// 执行中的调用已无人等待：取消执行，之后的调用重新执行
+		if call.waiters--; call.waiters == 0 && call.expires.IsZero() && my.calls[key] == call {
+			call.cancel()
+			delete(my.calls, key)
+		}
+		my.lock.Unlock()
+
+		var zero T
+		return zero, exists, ctx.Err()
+	}
+}
+
+// Forget 丢弃key的缓存结果或正在执行的调用：之后的调用重新执行，已在等待的调用方不受影响
+func (my *SingleFlight[T]) Forget(key string) {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	delete(my.calls, key)
+}
+
+// Len 正在执行或已缓存的key数量（包含已过期未清理的缓存）
+func (my *SingleFlight[T]) Len() int {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	return len(my.calls)
+}
+
+// execute 执行调用并保存结果：失败的结果不缓存
+func (my *SingleFlight[T]) execute(ctx context.Context, key string, call *flightCall[T], fn func(ctx context.Context) (T, error)) {
+	defer call.cancel()
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				call.err = fmt.Errorf("panic：%v", r)
+			}
+		}()
+
+		call.val, call.err = fn(ctx)
+	}()
+
+	my.lock.Lock()
+	if my.calls[key] == call {
+		if call.err == nil && my.cache > 0 {
+			call.expires = time.Now().Add(my.cache)
+		} else {
+			delete(my.calls, key)
+		}
+	}
+	my.lock.Unlock()
+
+	close(call.done)
+}
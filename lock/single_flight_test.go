@@ -0,0 +1,148 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitFlightWaiters 等待key的调用方数量达到n
+func waitFlightWaiters[T any](t *testing.T, sf *SingleFlight[T], key string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		sf.lock.Lock()
+		call, exists := sf.calls[key]
+		waiting := exists && call.waiters == n
+		sf.lock.Unlock()
+		if waiting {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("调用方没有进入等待：%s", key)
+}
+
+func TestSingleFlightDuplicate(t *testing.T) {
+	var (
+		sf      = NewSingleFlight[int]()
+		calls   atomic.Int32
+		release = make(chan struct{})
+		wg      sync.WaitGroup
+		owners  atomic.Int32
+	)
+
+	fn := func(context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			val, shared, err := sf.Do(context.Background(), "k", fn)
+			if err != nil || val != 42 {
+				t.Errorf("结果错误：%d %v", val, err)
+			}
+			if !shared {
+				owners.Add(1)
+			}
+		}()
+	}
+	waitFlightWaiters(t, sf, "k", 10)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("并发调用应只执行一次：%d", calls.Load())
+	}
+	if owners.Load() != 1 {
+		t.Fatalf("只有一个调用方的结果不是共享的：%d", owners.Load())
+	}
+	if sf.Len() != 0 {
+		t.Fatalf("不缓存时执行完成后应清理：%d", sf.Len())
+	}
+}
+
+func TestSingleFlightPanic(t *testing.T) {
+	sf := NewSingleFlight[int]()
+
+	_, _, err := sf.Do(context.Background(), "k", func(context.Context) (int, error) { panic("boom") })
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("panic应转换为错误：%v", err)
+	}
+	if sf.Len() != 0 {
+		t.Fatalf("panic后应清理：%d", sf.Len())
+	}
+
+	val, _, err := sf.Do(context.Background(), "k", func(context.Context) (int, error) { return 1, nil })
+	if err != nil || val != 1 {
+		t.Fatalf("panic后应可重新执行：%d %v", val, err)
+	}
+}
+
+func TestSingleFlightCache(t *testing.T) {
+	var (
+		sf    = NewSingleFlight[int]().SetCache(time.Minute)
+		calls atomic.Int32
+	)
+
+	fn := func(context.Context) (int, error) { return int(calls.Add(1)), nil }
+
+	if val, shared, _ := sf.Do(context.Background(), "k", fn); val != 1 || shared {
+		t.Fatalf("首次调用应执行：%d %v", val, shared)
+	}
+	if val, shared, _ := sf.Do(context.Background(), "k", fn); val != 1 || !shared {
+		t.Fatalf("缓存期内应返回缓存结果：%d %v", val, shared)
+	}
+
+	sf.Forget("k")
+	if val, _, _ := sf.Do(context.Background(), "k", fn); val != 2 {
+		t.Fatalf("丢弃缓存后应重新执行：%d", val)
+	}
+
+	// 失败的结果不缓存
+	failed := errors.New("failed")
+	if _, _, err := sf.Do(context.Background(), "e", func(context.Context) (int, error) { return 0, failed }); !errors.Is(err, failed) {
+		t.Fatalf("应返回fn的错误：%v", err)
+	}
+	if val, _, err := sf.Do(context.Background(), "e", fn); err != nil || val != 3 {
+		t.Fatalf("失败的结果不应缓存：%d %v", val, err)
+	}
+}
+
+func TestSingleFlightCancel(t *testing.T) {
+	var (
+		sf        = NewSingleFlight[int]()
+		cancelled = make(chan struct{})
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		waitFlightWaiters(t, sf, "k", 1)
+		cancel()
+	}()
+
+	_, _, err := sf.Do(ctx, "k", func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return 0, ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ctx取消应返回Canceled：%v", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("所有调用方放弃后应取消fn的ctx")
+	}
+}
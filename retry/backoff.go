@@ -0,0 +1,87 @@
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff 退避策略：attempt为已失败的次数（从1开始），previous为上一次的等待时间（第一次为0），返回下一次重试前的等待时间
+//
+// 退避策略只依赖参数计算，可以在多个重试器间并发复用
+type Backoff func(attempt int, previous time.Duration) time.Duration
+
+// ConstantBackoff 固定间隔
+func ConstantBackoff(delay time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration { return delay }
+}
+
+// LinearBackoff 线性增长：base + step * (attempt - 1)
+func LinearBackoff(base, step time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		return saturate(float64(base) + float64(step)*float64(attempt-1))
+	}
+}
+
+// ExponentialBackoff 指数增长：base * multiplier ^ (attempt - 1)
+func ExponentialBackoff(base time.Duration, multiplier float64) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		return saturate(float64(base) * math.Pow(multiplier, float64(attempt-1)))
+	}
+}
+
+// FibonacciBackoff 斐波那契增长：base * fib(attempt)，即base、base、2base、3base、5base……
+func FibonacciBackoff(base time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		a, b := 0.0, 1.0
+		for range attempt - 1 {
+			a, b = b, a+b
+		}
+
+		return saturate(float64(base) * b)
+	}
+}
+
+// DecorrelatedJitterBackoff 去相关抖动：在base与上一次等待时间的3倍之间随机取值，建议配合SetMaxDelay使用
+func DecorrelatedJitterBackoff(base time.Duration) Backoff {
+	return func(_ int, previous time.Duration) time.Duration {
+		previous = max(previous, base)
+		upper := saturate(float64(previous) * 3)
+		if upper <= base {
+			return base
+		}
+
+		return base + time.Duration(rand.Int63n(int64(upper-base)))
+	}
+}
+
+// WithMax 限制最大等待时间
+func (my Backoff) WithMax(maxDelay time.Duration) Backoff {
+	return func(attempt int, previous time.Duration) time.Duration {
+		return min(my(attempt, previous), maxDelay)
+	}
+}
+
+// WithJitter 增加随机抖动：在等待时间基础上随机增加[0, delay*factor)
+func (my Backoff) WithJitter(factor float64) Backoff {
+	return func(attempt int, previous time.Duration) time.Duration {
+		delay := my(attempt, previous)
+		if jitter := int64(float64(delay) * factor); jitter > 0 {
+			return saturate(float64(delay) + float64(rand.Int63n(jitter)))
+		}
+
+		return delay
+	}
+}
+
+// saturate 转换为等待时间：溢出时取最大值
+func saturate(delay float64) time.Duration {
+	if math.IsNaN(delay) || delay <= 0 {
+		return 0
+	}
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return time.Duration(delay)
+}
@@ -0,0 +1,342 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// BreakerState 熔断器状态
+	BreakerState int
+
+	// BreakerStateChange 熔断器状态变化回调
+	BreakerStateChange func(name string, from, to BreakerState)
+
+	// CircuitBreaker 熔断器：关闭状态下失败达到阈值后打开，打开状态下直接拒绝请求，冷却后进入半开状态放行有限的探测请求，探测全部成功后关闭
+	CircuitBreaker struct {
+		lock                sync.Mutex
+		name                string
+		state               BreakerState
+		generation          uint64
+		consecutiveFailures uint          // 连续失败阈值：0表示不使用
+		failureRate         float64       // 失败率阈值：0表示不使用
+		minRequests         uint          // 计算失败率的最少请求数
+		window              time.Duration // 关闭状态下的统计窗口：0表示不重置
+		openTimeout         time.Duration // 打开状态的冷却时间
+		halfOpenMax         uint          // 半开状态的探测请求数
+		isFailure           func(err error) bool
+		onStateChange       []BreakerStateChange
+		counts              BreakerCounts
+		windowStart         time.Time
+		openedAt            time.Time
+		halfOpenInFlight    uint
+		halfOpenSuccess     uint
+	}
+
+	// BreakerCounts 熔断器统计：状态变化或统计窗口重置时清零
+	BreakerCounts struct {
+		Requests            uint
+		Successes           uint
+		Failures            uint
+		ConsecutiveFailures uint
+	}
+)
+
+const (
+	BreakerStateClosed   BreakerState = iota // 关闭：正常放行
+	BreakerStateOpen                         // 打开：直接拒绝
+	BreakerStateHalfOpen                     // 半开：放行有限的探测请求
+)
+
+var (
+	CircuitBreakerApp CircuitBreaker
+
+	// BreakerIgnored 忽略本次结果：done(BreakerIgnored)只归还半开状态的探测名额，不计入成功或失败（如：调用方主动取消）
+	BreakerIgnored = errors.New("熔断器忽略本次结果")
+)
+
+// New 实例化：熔断器；默认连续失败5次打开，冷却30秒，半开状态探测1个请求
+func (*CircuitBreaker) New(name string) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:                name,
+		consecutiveFailures: 5,
+		openTimeout:         30 * time.Second,
+		halfOpenMax:         1,
+		isFailure:           func(err error) bool { return err != nil },
+		windowStart:         time.Now(),
+	}
+}
+
+// SetConsecutiveFailures 设置连续失败阈值：0表示不使用
+func (my *CircuitBreaker) SetConsecutiveFailures(consecutiveFailures uint) *CircuitBreaker {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.consecutiveFailures = consecutiveFailures
+
+	return my
+}
+
+// SetFailureRate 设置失败率阈值：统计窗口内请求数达到minRequests且失败率达到rate时打开；rate为0表示不使用，window为0表示不重置统计
+func (my *CircuitBreaker) SetFailureRate(rate float64, minRequests uint, window time.Duration) *CircuitBreaker {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.failureRate = rate
+	my.minRequests = max(minRequests, 1)
+	my.window = window
+
+	return my
+}
+
+// SetOpenTimeout 设置打开状态的冷却时间
+func (my *CircuitBreaker) SetOpenTimeout(openTimeout time.Duration) *CircuitBreaker {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.openTimeout = openTimeout
+
+	return my
+}
+
+// SetHalfOpenMaxRequests 设置半开状态的探测请求数：探测请求全部成功后关闭
+func (my *CircuitBreaker) SetHalfOpenMaxRequests(halfOpenMax uint) *CircuitBreaker {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.halfOpenMax = max(halfOpenMax, 1)
+
+	return my
+}
+
+// SetIsFailure 设置失败判断：默认err不为nil即失败；可用于忽略业务错误
+func (my *CircuitBreaker) SetIsFailure(isFailure func(err error) bool) *CircuitBreaker {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.isFailure = isFailure
+
+	return my
+}
+
+// OnStateChange 添加状态变化回调
+func (my *CircuitBreaker) OnStateChange(fn BreakerStateChange) *CircuitBreaker {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	my.onStateChange = append(my.onStateChange, fn)
+
+	return my
+}
+
+// GetName 获取名称
+func (my *CircuitBreaker) GetName() string { return my.name }
+
+// State 获取当前状态
+func (my *CircuitBreaker) State() BreakerState {
+	my.lock.Lock()
+	changes := my.refresh(time.Now())
+	state := my.state
+	my.lock.Unlock()
+
+	my.notify(changes)
+
+	return state
+}
+
+// Counts 获取当前统计
+func (my *CircuitBreaker) Counts() BreakerCounts {
+	my.lock.Lock()
+	defer my.lock.Unlock()
+
+	return my.counts
+}
+
+// Allow 申请执行：熔断器打开或半开状态探测请求已满时返回BreakerOpenErr；通过时需调用done报告结果，结果无法说明服务状态时传入BreakerIgnored
+func (my *CircuitBreaker) Allow() (func(err error), error) {
+	my.lock.Lock()
+
+	now := time.Now()
+	changes := my.refresh(now)
+
+	switch {
+	case my.state == BreakerStateOpen:
+		my.lock.Unlock()
+		my.notify(changes)
+		return nil, BreakerOpenErr.New(my.name)
+	case my.state == BreakerStateHalfOpen && my.halfOpenInFlight >= my.halfOpenMax:
+		my.lock.Unlock()
+		my.notify(changes)
+		return nil, BreakerOpenErr.New(fmt.Sprintf("%s：半开状态探测请求已满", my.name))
+	case my.state == BreakerStateHalfOpen:
+		my.halfOpenInFlight++
+	}
+
+	generation := my.generation
+	my.lock.Unlock()
+	my.notify(changes)
+
+	var once sync.Once
+
+	return func(err error) { once.Do(func() { my.done(generation, err) }) }, nil
+}
+
+// Execute 通过熔断器执行fn：熔断器打开时不执行fn并返回BreakerOpenErr
+func (my *CircuitBreaker) Execute(fn func() error) error {
+	done, err := my.Allow()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			done(fmt.Errorf("panic：%v", r))
+			panic(r)
+		}
+	}()
+
+	err = fn()
+	done(err)
+
+	return err
+}
+
+// Call 通过熔断器执行有返回值的方法：熔断器打开时不执行fn并返回BreakerOpenErr
+func Call[T any](breaker *CircuitBreaker, fn func() (T, error)) (T, error) {
+	var val T
+
+	err := breaker.Execute(func() (err error) {
+		val, err = fn()
+		return err
+	})
+
+	return val, err
+}
+
+// done 报告执行结果：状态已变化时忽略旧状态下的结果
+func (my *CircuitBreaker) done(generation uint64, err error) {
+	my.lock.Lock()
+
+	now := time.Now()
+	changes := my.refresh(now)
+	if generation != my.generation {
+		my.lock.Unlock()
+		my.notify(changes)
+		return
+	}
+
+	if errors.Is(err, BreakerIgnored) {
+		if my.state == BreakerStateHalfOpen {
+			my.halfOpenInFlight--
+		}
+		my.lock.Unlock()
+		my.notify(changes)
+		return
+	}
+
+	failed := my.isFailure(err)
+	my.counts.Requests++
+	if failed {
+		my.counts.Failures++
+		my.counts.ConsecutiveFailures++
+	} else {
+		my.counts.Successes++
+		my.counts.ConsecutiveFailures = 0
+	}
+
+	switch my.state {
+	case BreakerStateClosed:
+		if failed && my.shouldTrip() {
+			changes = append(changes, my.setState(BreakerStateOpen, now))
+		}
+	case BreakerStateHalfOpen:
+		my.halfOpenInFlight--
+		if failed {
+			changes = append(changes, my.setState(BreakerStateOpen, now))
+		} else if my.halfOpenSuccess++; my.halfOpenSuccess >= my.halfOpenMax {
+			changes = append(changes, my.setState(BreakerStateClosed, now))
+		}
+	}
+
+	my.lock.Unlock()
+	my.notify(changes)
+}
+
+// shouldTrip 是否达到打开阈值（调用方需持有锁）
+func (my *CircuitBreaker) shouldTrip() bool {
+	if my.consecutiveFailures > 0 && my.counts.ConsecutiveFailures >= my.consecutiveFailures {
+		return true
+	}
+
+	if my.failureRate > 0 && my.counts.Requests >= my.minRequests {
+		return float64(my.counts.Failures)/float64(my.counts.Requests) >= my.failureRate
+	}
+
+	return false
+}
+
+// refresh 按时间刷新状态：冷却结束进入半开状态，统计窗口结束清零统计（调用方需持有锁）
+func (my *CircuitBreaker) refresh(now time.Time) [][2]BreakerState {
+	switch my.state {
+	case BreakerStateOpen:
+		if now.Sub(my.openedAt) >= my.openTimeout {
+			return [][2]BreakerState{my.setState(BreakerStateHalfOpen, now)}
+		}
+	case BreakerStateClosed:
+		if my.window > 0 && now.Sub(my.windowStart) >= my.window {
+			my.counts = BreakerCounts{}
+			my.windowStart = now
+		}
+	}
+
+	return nil
+}
+
+// setState 切换状态并清零统计（调用方需持有锁）
+func (my *CircuitBreaker) setState(state BreakerState, now time.Time) [2]BreakerState {
+	from := my.state
+
+	my.state = state
+	my.generation++
+	my.counts = BreakerCounts{}
+	my.windowStart = now
+	my.halfOpenInFlight = 0
+	my.halfOpenSuccess = 0
+	if state == BreakerStateOpen {
+		my.openedAt = now
+	}
+
+	return [2]BreakerState{from, state}
+}
+
+// notify 调用状态变化回调（不持有锁）
+func (my *CircuitBreaker) notify(changes [][2]BreakerState) {
+	if len(changes) == 0 {
+		return
+	}
+
+	my.lock.Lock()
+	callbacks := my.onStateChange
+	my.lock.Unlock()
+
+	for _, change := range changes {
+		for _, fn := range callbacks {
+			fn(my.name, change[0], change[1])
+		}
+	}
+}
+
+func (my BreakerState) String() string {
+	switch my {
+	case BreakerStateClosed:
+		return "closed"
+	case BreakerStateOpen:
+		return "open"
+	case BreakerStateHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(my))
+	}
+}
@@ -0,0 +1,34 @@
+package retry
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jericho-yu/aid/array"
+	"github.com/jericho-yu/aid/myError"
+	op "github.com/jericho-yu/aid/operation"
+)
+
+type (
+	BreakerOpenError struct{ myError.MyError }
+)
+
+var (
+	BreakerOpenErr BreakerOpenError
+)
+
+func (*BreakerOpenError) New(msg string) myError.IMyError {
+	return &BreakerOpenError{myError.MyError{Msg: array.NewDestruction("熔断器已打开", msg).JoinWithoutEmpty("：")}}
+}
+
+func (*BreakerOpenError) Wrap(err error) myError.IMyError {
+	return &BreakerOpenError{myError.MyError{Msg: fmt.Errorf("熔断器已打开"+op.Ternary(err != nil, "：%w", "%w"), err).Error()}}
+}
+
+func (*BreakerOpenError) Panic() myError.IMyError {
+	return &BreakerOpenError{myError.MyError{Msg: "熔断器已打开"}}
+}
+
+func (my *BreakerOpenError) Error() string { return my.Msg }
+
+func (my *BreakerOpenError) Is(target error) bool { return reflect.DeepEqual(target, &BreakerOpenErr) }
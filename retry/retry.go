@@ -2,15 +2,39 @@ package retry
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/rand"
 	"time"
 )
 
-type Retry struct {
-	sleep time.Duration
-	fn    func() error
-	ctx   context.Context
-}
+type (
+	Retry struct {
+		sleep      time.Duration
+		fn         func() error
+		ctx        context.Context
+		backoff    Backoff
+		maxDelay   time.Duration
+		maxElapsed time.Duration
+		retryIf    func(err error) bool
+		onRetry    []OnRetry
+		breaker    *CircuitBreaker
+	}
+
+	// OnRetry 重试回调：attempt为已失败的次数，err为本次错误，delay为下一次重试前的等待时间
+	OnRetry func(attempt int, err error, delay time.Duration)
+
+	// PermanentError 永久错误：不再重试
+	PermanentError struct{ Err error }
+
+	// RetryError 重试最终失败的错误
+	RetryError struct {
+		Errors   []error       // 每一次的错误：最后一个为最终错误（永久错误为其包装的错误，上下文取消时为上下文的错误）
+		Attempts int           // 执行次数
+		Elapsed  time.Duration // 总耗时
+		start    time.Time
+	}
+)
 
 var RetryApp Retry
 
@@ -38,81 +62,252 @@ func (my *Retry) SetCtx(ctx context.Context) *Retry {
 	return my
 }
 
+// SetBackoff 设置退避策略：用于Run和Do，默认为以重试间隔为基数的指数退避
+func (my *Retry) SetBackoff(backoff Backoff) *Retry {
+	my.backoff = backoff
+
+	return my
+}
+
+// SetMaxDelay 设置单次最大等待时间：0表示不限制
+func (my *Retry) SetMaxDelay(maxDelay time.Duration) *Retry {
+	my.maxDelay = maxDelay
+
+	return my
+}
+
+// SetMaxElapsed 设置总耗时预算：下一次重试会超出预算时不再重试，0表示不限制
+func (my *Retry) SetMaxElapsed(maxElapsed time.Duration) *Retry {
+	my.maxElapsed = maxElapsed
+
+	return my
+}
+
+// SetRetryIf 设置重试条件：返回false时不再重试，默认除永久错误外都重试
+func (my *Retry) SetRetryIf(retryIf func(err error) bool) *Retry {
+	my.retryIf = retryIf
+
+	return my
+}
+
+// SetBreaker 设置熔断器：每次执行都经过熔断器，熔断器打开时立即停止重试并返回BreakerOpenErr
+func (my *Retry) SetBreaker(breaker *CircuitBreaker) *Retry {
+	my.breaker = breaker
+
+	return my
+}
+
+// OnRetry 添加重试回调：每次重试等待前按添加顺序调用
+func (my *Retry) OnRetry(fn OnRetry) *Retry {
+	my.onRetry = append(my.onRetry, fn)
+
+	return my
+}
+
+// Run 按退避策略重试：最多执行attempts次，返回最后一次的错误；ctx取消时返回ctx的错误
+func (my *Retry) Run(attempts int) error { return my.run(attempts, my.getBackoff()) }
+
 // Simple 线性重试
 func (my *Retry) Simple(attempts int) error {
+	return my.run(attempts, ConstantBackoff(my.sleep))
+}
+
+// Do 指数退避
+func (my *Retry) Do(attempts int) error {
+	return my.run(attempts, ExponentialBackoff(my.sleep, 2))
+}
+
+// WithContext 带上下文的重试
+func (my *Retry) WithContext(attempts int) error {
+	return my.run(attempts, ExponentialBackoff(my.sleep, 2))
+}
+
+func (my *Retry) WithContextAndJitter(attempts int) error {
+	// 加入随机退避：每次等待时间在上一次基础上随机增加后翻倍
+	return my.run(attempts, func(attempt int, previous time.Duration) time.Duration {
+		if previous == 0 {
+			previous = my.sleep
+		} else {
+			previous *= 2
+		}
+		if previous <= 0 {
+			return 0
+		}
+
+		return previous + time.Duration(rand.Int63n(int64(previous)))
+	})
+}
+
+// run 迭代执行重试：返回最后一次的错误
+func (my *Retry) run(attempts int, backoff Backoff) error {
 	if my.fn == nil {
 		return nil
 	}
 
-	if err := my.fn(); err != nil {
-		if attempts--; attempts > 0 {
-			time.Sleep(my.sleep)
-			return my.Simple(attempts)
-		}
-		return err
+	_, err := execute(my, attempts, backoff, func(context.Context, int) (struct{}, error) { return struct{}{}, my.fn() })
+	if err != nil {
+		return err.Last()
 	}
 
 	return nil
 }
 
-// Do 指数退避
-func (my *Retry) Do(attempts int) error {
-	if my.fn == nil {
-		return nil
+// Do 按重试器的退避策略执行有返回值的方法：最多执行attempts次，fn接收重试器的上下文和当前次数（从1开始）；retry为nil时使用默认重试器
+//
+// 最终失败时返回*RetryError，包含每一次的错误、执行次数和总耗时，可使用errors.Is、errors.As匹配其中任意一次的错误
+func Do[T any](retry *Retry, attempts int, fn func(ctx context.Context, attempt int) (T, error)) (T, error) {
+	if retry == nil {
+		retry = RetryApp.New()
 	}
 
-	if err := my.fn(); err != nil {
-		if attempts--; attempts > 0 {
-			time.Sleep(my.sleep)
-			return my.SetSleep(2 * my.sleep).Do(attempts)
-		}
-		return err
+	val, err := execute(retry, attempts, retry.getBackoff(), fn)
+	if err != nil {
+		return val, err
 	}
 
-	return nil
+	return val, nil
 }
 
-// WithContext 带上下文的重试
-func (my *Retry) WithContext(attempts int) error {
-	if my.fn == nil {
-		return nil
+// execute 迭代执行重试：只使用局部状态，同一个重试器可以并发执行
+func execute[T any](my *Retry, attempts int, backoff Backoff, fn func(ctx context.Context, attempt int) (T, error)) (T, *RetryError) {
+	var (
+		ctx   = my.ctx
+		delay time.Duration
+		zero  T
+		wrong = &RetryError{start: time.Now()}
+	)
+	if ctx == nil {
+		ctx = context.Background()
 	}
 
-	if err := my.fn(); err != nil {
-		if attempts--; attempts > 0 {
-			select {
-			case <-time.After(my.sleep):
-				return my.SetSleep(2 * my.sleep).WithContext(attempts) // 指数退避
-			case <-my.ctx.Done():
-				return my.ctx.Err()
-			}
+	for attempt := 1; ; attempt++ {
+		val, err := call(my, ctx, attempt, fn)
+		if err == nil {
+			return val, nil
+		}
+
+		wrong.Attempts = attempt
+		retry, final := my.shouldRetry(err)
+		if !retry {
+			return zero, wrong.append(final)
+		}
+		if attempt >= attempts {
+			return zero, wrong.append(err)
+		}
+
+		delay = backoff(attempt, delay)
+		if my.maxDelay > 0 {
+			delay = min(delay, my.maxDelay)
+		}
+		if my.maxElapsed > 0 && time.Since(wrong.start)+delay > my.maxElapsed {
+			return zero, wrong.append(err)
+		}
+
+		for _, fn := range my.onRetry {
+			fn(attempt, err, delay)
+		}
+
+		wrong.Errors = append(wrong.Errors, err)
+		if err = sleep(ctx, delay); err != nil {
+			return zero, wrong.append(err)
 		}
-		return err
 	}
+}
 
-	return nil
+// call 执行一次：设置了熔断器时经过熔断器，熔断器打开时返回永久错误；fn panic时计为失败后继续panic
+func call[T any](my *Retry, ctx context.Context, attempt int, fn func(ctx context.Context, attempt int) (T, error)) (T, error) {
+	if my.breaker == nil {
+		return fn(ctx, attempt)
+	}
+
+	done, err := my.breaker.Allow()
+	if err != nil {
+		var zero T
+		return zero, Permanent(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			done(fmt.Errorf("panic：%v", r))
+			panic(r)
+		}
+	}()
+
+	val, err := fn(ctx, attempt)
+	done(err)
+
+	return val, err
 }
 
-func (my *Retry) WithContextAndJitter(attempts int) error {
-	if my.fn == nil {
+// getBackoff 获取退避策略
+func (my *Retry) getBackoff() Backoff {
+	if my.backoff == nil {
+		return ExponentialBackoff(my.sleep, 2)
+	}
+
+	return my.backoff
+}
+
+// shouldRetry 是否重试：永久错误返回其包装的错误
+func (my *Retry) shouldRetry(err error) (bool, error) {
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		return false, permanent.Err
+	}
+
+	if my.retryIf != nil && !my.retryIf(err) {
+		return false, err
+	}
+
+	return true, err
+}
+
+// sleep 等待：ctx取消时返回ctx的错误
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+}
 
-	if err := my.fn(); err != nil {
-		if attempts--; attempts > 0 {
-			// 加入随机退避
-			jitter := time.Duration(rand.Int63n(int64(my.sleep)))
-			my.sleep = my.sleep + jitter
+// Permanent 包装为永久错误：重试器遇到永久错误时立即返回被包装的错误
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
 
-			select {
-			case <-time.After(my.sleep):
-				return my.SetSleep(2 * my.sleep).WithContextAndJitter(attempts) // 指数退避
-			case <-my.ctx.Done():
-				return my.ctx.Err()
-			}
-		}
-		return err
+	return &PermanentError{Err: err}
+}
+
+func (my *PermanentError) Error() string { return my.Err.Error() }
+
+func (my *PermanentError) Unwrap() error { return my.Err }
+
+// append 记录最终错误
+func (my *RetryError) append(err error) *RetryError {
+	my.Errors = append(my.Errors, err)
+	my.Elapsed = time.Since(my.start)
+
+	return my
+}
+
+func (my *RetryError) Error() string {
+	return fmt.Sprintf("重试失败（%d次，耗时%s）：%v", my.Attempts, my.Elapsed, my.Last())
+}
+
+// Unwrap 支持errors.Is、errors.As匹配每一次的错误
+func (my *RetryError) Unwrap() []error { return my.Errors }
+
+// Last 获取最终错误
+func (my *RetryError) Last() error {
+	if len(my.Errors) == 0 {
+		return nil
 	}
 
-	return nil
+	return my.Errors[len(my.Errors)-1]
 }
@@ -45,3 +45,244 @@ func Test3(t *testing.T) {
 		}
 	})
 }
+
+func Test4(t *testing.T) {
+	t.Run("test4 退避策略", func(t *testing.T) {
+		base := 10 * time.Millisecond
+
+		for attempt, expected := range []time.Duration{10, 10, 20, 30, 50, 80} {
+			if delay := FibonacciBackoff(base)(attempt+1, 0); delay != expected*time.Millisecond {
+				t.Fatalf("斐波那契退避错误：%d %s", attempt+1, delay)
+			}
+		}
+
+		if delay := ExponentialBackoff(base, 2)(4, 0); delay != 80*time.Millisecond {
+			t.Fatalf("指数退避错误：%s", delay)
+		}
+		if delay := LinearBackoff(base, base)(3, 0); delay != 30*time.Millisecond {
+			t.Fatalf("线性退避错误：%s", delay)
+		}
+		if delay := ExponentialBackoff(base, 2).WithMax(time.Second)(100, 0); delay != time.Second {
+			t.Fatalf("最大等待时间错误：%s", delay)
+		}
+		for range 100 {
+			if delay := DecorrelatedJitterBackoff(base)(2, 20*time.Millisecond); delay < base || delay >= 60*time.Millisecond {
+				t.Fatalf("去相关抖动错误：%s", delay)
+			}
+		}
+	})
+
+	t.Run("test4 重试条件与永久错误", func(t *testing.T) {
+		var (
+			attempts int
+			hooks    []int
+			fatal    = errors.New("fatal")
+		)
+
+		err := RetryApp.New().
+			SetBackoff(ConstantBackoff(time.Millisecond)).
+			SetFn(func() error {
+				if attempts++; attempts == 3 {
+					return Permanent(fatal)
+				}
+				return errors.New("transient error")
+			}).
+			OnRetry(func(attempt int, err error, delay time.Duration) { hooks = append(hooks, attempt) }).
+			Run(10)
+		if err != fatal || attempts != 3 || len(hooks) != 2 {
+			t.Fatalf("永久错误未停止重试：%v %d %v", err, attempts, hooks)
+		}
+
+		attempts = 0
+		err = RetryApp.New().
+			SetFn(func() error { attempts++; return fatal }).
+			SetRetryIf(func(err error) bool { return !errors.Is(err, fatal) }).
+			Run(10)
+		if err != fatal || attempts != 1 {
+			t.Fatalf("重试条件未生效：%v %d", err, attempts)
+		}
+	})
+
+	t.Run("test4 总耗时预算", func(t *testing.T) {
+		attempts := 0
+		start := time.Now()
+
+		err := RetryApp.New().
+			SetBackoff(ConstantBackoff(20 * time.Millisecond)).
+			SetMaxElapsed(50 * time.Millisecond).
+			SetFn(func() error { attempts++; return errors.New("transient error") }).
+			Run(100)
+		if err == nil || attempts < 2 || attempts > 3 || time.Since(start) > 100*time.Millisecond {
+			t.Fatalf("总耗时预算未生效：%d %s", attempts, time.Since(start))
+		}
+	})
+}
+
+func Test5(t *testing.T) {
+	t.Run("test5 有返回值的重试", func(t *testing.T) {
+		val, err := Do(RetryApp.New().SetSleep(time.Millisecond), 5, func(ctx context.Context, attempt int) (string, error) {
+			if attempt < 3 {
+				return "", errors.New("transient error")
+			}
+			return fmt.Sprintf("第%d次成功", attempt), nil
+		})
+		if err != nil || val != "第3次成功" {
+			t.Fatalf("结果错误：%s %v", val, err)
+		}
+	})
+
+	t.Run("test5 汇总每一次的错误", func(t *testing.T) {
+		first := errors.New("first")
+
+		_, err := Do(RetryApp.New().SetSleep(time.Millisecond), 3, func(ctx context.Context, attempt int) (int, error) {
+			if attempt == 1 {
+				return 0, first
+			}
+			return 0, fmt.Errorf("第%d次失败", attempt)
+		})
+
+		var retryErr *RetryError
+		if !errors.As(err, &retryErr) || retryErr.Attempts != 3 || len(retryErr.Errors) != 3 || retryErr.Elapsed <= 0 {
+			t.Fatalf("错误汇总错误：%v", err)
+		}
+		if !errors.Is(err, first) {
+			t.Fatalf("应能匹配第一次的错误：%v", err)
+		}
+	})
+
+	t.Run("test5 上下文取消", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := Do(RetryApp.New().SetCtx(ctx).SetSleep(time.Second), 3, func(ctx context.Context, attempt int) (int, error) {
+			return 0, errors.New("transient error")
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("应返回上下文错误：%v", err)
+		}
+	})
+}
+
+func Test6(t *testing.T) {
+	t.Run("test6 熔断器状态变化", func(t *testing.T) {
+		var changes []string
+		failed := errors.New("failed")
+
+		breaker := CircuitBreakerApp.New("test").
+			SetConsecutiveFailures(2).
+			SetOpenTimeout(20 * time.Millisecond).
+			OnStateChange(func(name string, from, to BreakerState) { changes = append(changes, from.String()+"->"+to.String()) })
+
+		_ = breaker.Execute(func() error { return failed })
+		_ = breaker.Execute(func() error { return failed })
+		if breaker.State() != BreakerStateOpen {
+			t.Fatalf("连续失败后应打开：%s", breaker.State())
+		}
+
+		if err := breaker.Execute(func() error { return nil }); !errors.Is(err, &BreakerOpenErr) {
+			t.Fatalf("打开状态应拒绝请求：%v", err)
+		}
+
+		time.Sleep(30 * time.Millisecond)
+		if val, err := Call(breaker, func() (int, error) { return 1, nil }); err != nil || val != 1 {
+			t.Fatalf("半开状态探测请求失败：%d %v", val, err)
+		}
+		if breaker.State() != BreakerStateClosed {
+			t.Fatalf("探测成功后应关闭：%s", breaker.State())
+		}
+
+		if fmt.Sprint(changes) != "[closed->open open->half-open half-open->closed]" {
+			t.Fatalf("状态变化错误：%v", changes)
+		}
+	})
+
+	t.Run("test6 失败率阈值", func(t *testing.T) {
+		breaker := CircuitBreakerApp.New("rate").SetConsecutiveFailures(0).SetFailureRate(0.5, 4, time.Minute)
+
+		for _, failed := range []bool{false, true, false, true} {
+			_ = breaker.Execute(func() error {
+				if failed {
+					return errors.New("failed")
+				}
+				return nil
+			})
+		}
+		if breaker.State() != BreakerStateOpen {
+			t.Fatalf("失败率达到阈值后应打开：%s %+v", breaker.State(), breaker.Counts())
+		}
+	})
+
+	t.Run("test6 忽略结果", func(t *testing.T) {
+		breaker := CircuitBreakerApp.New("ignore").SetConsecutiveFailures(2).SetOpenTimeout(10 * time.Millisecond)
+
+		_ = breaker.Execute(func() error { return errors.New("failed") })
+		_ = breaker.Execute(func() error { return BreakerIgnored })
+		if counts := breaker.Counts(); counts.Requests != 1 || counts.ConsecutiveFailures != 1 {
+			t.Fatalf("忽略的结果不应计入统计：%+v", counts)
+		}
+		_ = breaker.Execute(func() error { return errors.New("failed") })
+		if breaker.State() != BreakerStateOpen {
+			t.Fatalf("连续失败后应打开：%s", breaker.State())
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		done, err := breaker.Allow()
+		if err != nil {
+			t.Fatalf("半开状态应放行探测请求：%v", err)
+		}
+		if _, err = breaker.Allow(); !errors.Is(err, &BreakerOpenErr) {
+			t.Fatalf("探测名额已满时应拒绝：%v", err)
+		}
+		done(BreakerIgnored)
+		if breaker.State() != BreakerStateHalfOpen {
+			t.Fatalf("忽略的探测结果不应改变状态：%s", breaker.State())
+		}
+		if done, err = breaker.Allow(); err != nil {
+			t.Fatalf("探测名额应已归还：%v", err)
+		}
+		done(nil)
+		if breaker.State() != BreakerStateClosed {
+			t.Fatalf("探测成功后应关闭：%s", breaker.State())
+		}
+	})
+
+	t.Run("test6 熔断器打开后停止重试", func(t *testing.T) {
+		attempts := 0
+		breaker := CircuitBreakerApp.New("retry").SetConsecutiveFailures(2)
+
+		err := RetryApp.New().
+			SetBackoff(ConstantBackoff(time.Millisecond)).
+			SetBreaker(breaker).
+			SetFn(func() error { attempts++; return errors.New("transient error") }).
+			Run(10)
+		if attempts != 2 || !errors.Is(err, &BreakerOpenErr) {
+			t.Fatalf("熔断器打开后应停止重试：%d %v", attempts, err)
+		}
+	})
+}
+
+func Test7(t *testing.T) {
+	t.Run("test7 重试中panic时归还熔断器的半开配额", func(t *testing.T) {
+		breaker := CircuitBreakerApp.New("panic").SetConsecutiveFailures(1).SetOpenTimeout(10 * time.Millisecond)
+
+		_ = breaker.Execute(func() error { return errors.New("failed") })
+		time.Sleep(20 * time.Millisecond)
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("应继续panic")
+				}
+			}()
+			_, _ = Do(RetryApp.New().SetBreaker(breaker), 1, func(context.Context, int) (int, error) { panic("boom") })
+		}()
+		if breaker.State() != BreakerStateOpen {
+			t.Fatalf("半开状态panic应计为失败：%s", breaker.State())
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		if err := breaker.Execute(func() error { return nil }); err != nil || breaker.State() != BreakerStateClosed {
+			t.Fatalf("冷却后探测请求应通过：%v %s", err, breaker.State())
+		}
+	})
+}